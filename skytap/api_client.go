@@ -0,0 +1,244 @@
+package skytap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skytap/skytap-sdk-go/skytap"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+const apiMediaType = "application/json"
+
+// listPageSize is the number of results requested for each page of a list endpoint
+const listPageSize = 100
+
+// defRetryAfter is the number of seconds to wait before retrying a request when the response does not say,
+// as in the SDK client
+const defRetryAfter = 10
+
+// apiClient issues requests to the Skytap API endpoints which are not covered by the SDK.
+// It shares the base URL, user agent and credentials of the SDK client, and retries busy responses the same way.
+type apiClient struct {
+	hc          *http.Client
+	baseURL     *url.URL
+	userAgent   string
+	credentials skytap.CredentialsProvider
+	retryCount  int
+	retryAfter  int
+}
+
+// newAPIClient creates an apiClient for the SDK client. The SDK client always uses http.DefaultClient, which it
+// does not expose, so it is used here too.
+func newAPIClient(client *skytap.Client, retryCount int) *apiClient {
+	return &apiClient{
+		hc:          http.DefaultClient,
+		baseURL:     client.BaseURL,
+		userAgent:   client.UserAgent,
+		credentials: client.Credentials,
+		retryCount:  retryCount,
+		retryAfter:  defRetryAfter,
+	}
+}
+
+// do sends the request and decodes the response into v. Unsuccessful responses are returned as a
+// *skytap.ErrorResponse so they can be handled like SDK errors. Responses saying the resource is busy
+// (409, 423, 429 and 422 with a busy message) are retried up to the retry count, waiting for the
+// Retry-After header if there is one.
+func (c *apiClient) do(ctx context.Context, method string, path string, body interface{}, v interface{}) error {
+	rel, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+	u := c.baseURL.ResolveReference(rel)
+
+	var data []byte
+	if body != nil {
+		data, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		log.Printf("[DEBUG] API request body (%s)", strings.TrimSpace(string(data)))
+	}
+
+	for i := 0; ; i++ {
+		// If the context has already been cancelled, then give up retrying early
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		errorResponse, err := c.send(ctx, method, u, data, v)
+		if err != nil || errorResponse == nil {
+			return err
+		}
+		seconds, retry := c.retryAfterError(errorResponse)
+		if !retry || i+1 >= c.retryCount {
+			return errorResponse
+		}
+
+		log.Printf("[INFO] API request (%s), URL (%s) returned %d. Retrying after %d second(s)",
+			method, u.String(), errorResponse.Response.StatusCode, seconds)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(seconds) * time.Second):
+		}
+	}
+}
+
+// send sends the request once. An unsuccessful response is returned as a *skytap.ErrorResponse rather than an error
+// so that it can be retried.
+func (c *apiClient) send(ctx context.Context, method string, u *url.URL, data []byte, v interface{}) (*skytap.ErrorResponse, error) {
+	var buf io.Reader
+	if data != nil {
+		buf = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", apiMediaType)
+	}
+	req.Header.Set("Accept", apiMediaType)
+	req.Header.Set("User-Agent", c.userAgent)
+
+	auth, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	log.Printf("[DEBUG] API request (%s), URL (%s)", req.Method, req.URL.String())
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		errorResponse := &skytap.ErrorResponse{Response: resp}
+		if data, err := ioutil.ReadAll(resp.Body); err == nil && len(data) > 0 {
+			message := string(data)
+			errorResponse.Message = &message
+		}
+		if requestID := resp.Header.Get("X-Request-ID"); requestID != "" {
+			errorResponse.RequestID = &requestID
+		}
+		retryAfter := c.retryAfter
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			retryAfter = seconds
+		}
+		errorResponse.RetryAfter = &retryAfter
+		return errorResponse, nil
+	}
+
+	if v != nil {
+		return nil, json.NewDecoder(resp.Body).Decode(v)
+	}
+	return nil, nil
+}
+
+// retryAfterError returns whether the error response is one the SDK client retries, and how many seconds to wait
+func (c *apiClient) retryAfterError(errorResponse *skytap.ErrorResponse) (int, bool) {
+	switch errorResponse.Response.StatusCode {
+	case http.StatusUnprocessableEntity:
+		if errorResponse.Message == nil || !strings.Contains(*errorResponse.Message, "busy") {
+			return 0, false
+		}
+	case http.StatusConflict, http.StatusLocked, http.StatusTooManyRequests:
+	default:
+		return 0, false
+	}
+	return *errorResponse.RetryAfter, true
+}
+
+// list requests every page of a list endpoint, applying the filters to the query, and calls add with each result.
+// The SDK list functions only request the first page.
+func (c *apiClient) list(ctx context.Context, path string, filters []skytap.ListFilter, add func(json.RawMessage) error) error {
+	for offset := 0; ; offset += listPageSize {
+		var page []json.RawMessage
+		if err := c.do(ctx, "GET", listPath(path, listPageSize, offset, filters), nil, &page); err != nil {
+			return err
+		}
+		for _, v := range page {
+			if err := add(v); err != nil {
+				return err
+			}
+		}
+		if len(page) < listPageSize {
+			return nil
+		}
+	}
+}
+
+// listPath adds the paging and filter parameters to the path, in the format used by the SDK.
+// Any query parameters already in the path, such as the scope, are kept.
+func listPath(path string, count int, offset int, filters []skytap.ListFilter) string {
+	q := url.Values{}
+	if i := strings.Index(path, "?"); i >= 0 {
+		if v, err := url.ParseQuery(path[i+1:]); err == nil {
+			q = v
+		}
+		path = path[:i]
+	}
+	q.Add("count", strconv.Itoa(count))
+	q.Add("offset", strconv.Itoa(offset))
+
+	var query []string
+	for _, f := range filters {
+		if f.Name != nil && f.Value != nil {
+			query = append(query, *f.Name+":"+*f.Value)
+		}
+	}
+	if len(query) > 0 {
+		q.Add("query", strings.Join(query, ","))
+	}
+
+	return path + "?" + q.Encode()
+}
+
+// nameListFilters returns the API query filter for a name. The API matches the names containing the value,
+// so the results still need to be filtered. Names which would break the query format are not sent.
+func nameListFilters(name string) []skytap.ListFilter {
+	if name == "" || strings.ContainsAny(name, ",:") {
+		return nil
+	}
+	return []skytap.ListFilter{{Name: utils.String("name"), Value: utils.String(name)}}
+}
+
+// regexNameListFilters returns the API query filter for a name regex, using the longest literal string that every
+// matching name must contain. The regex itself is only applied to the results.
+func regexNameListFilters(pattern string) []skytap.ListFilter {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	re = re.Simplify()
+
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+	var literal string
+	for _, sub := range subs {
+		if sub.Op == syntax.OpLiteral && sub.Flags&syntax.FoldCase == 0 && len(sub.Rune) > len([]rune(literal)) {
+			literal = string(sub.Rune)
+		}
+	}
+	return nameListFilters(literal)
+}
@@ -0,0 +1,191 @@
+package skytap
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func testAPIClient(t *testing.T, handler http.HandlerFunc) (*apiClient, func()) {
+	server := httptest.NewServer(handler)
+	client, err := skytap.NewClient(skytap.NewDefaultSettings(
+		skytap.WithBaseURL(server.URL+"/"),
+		skytap.WithUserAgent("test-agent"),
+		skytap.WithCredentialsProvider(skytap.NewAPITokenCredentials("user", "token")),
+	))
+	assert.NoError(t, err)
+	return newAPIClient(client, 3), server.Close
+}
+
+func TestAPIClientDo(t *testing.T) {
+	client, closeServer := testAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/v2/configurations/123.json", r.URL.Path)
+		assert.Equal(t, "test-agent", r.UserAgent())
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, true, body["sequencing_enabled"])
+
+		_, _ = w.Write([]byte(`{"id": "123", "sequencing_enabled": true}`))
+	})
+	defer closeServer()
+
+	var environment skytap.Environment
+	err := client.do(context.Background(), "PUT", "v2/configurations/123.json",
+		&environmentSequencingRequest{SequencingEnabled: utils.Bool(true)}, &environment)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", *environment.ID)
+	assert.True(t, *environment.SequencingEnabled)
+}
+
+func TestAPIClientDoNotFound(t *testing.T) {
+	client, closeServer := testAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "not found"}`))
+	})
+	defer closeServer()
+
+	err := client.do(context.Background(), "GET", "v2/configurations/123.json", nil, nil)
+	assert.Error(t, err)
+	assert.True(t, utils.ResponseErrorIsNotFound(err))
+}
+
+func TestAPIClientDoRetriesLocked(t *testing.T) {
+	var requests int
+	client, closeServer := testAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, true, body["sequencing_enabled"])
+
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusLocked)
+			_, _ = w.Write([]byte(`{"error": "the environment is busy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "123", "sequencing_enabled": true}`))
+	})
+	defer closeServer()
+
+	var environment skytap.Environment
+	err := client.do(context.Background(), "PUT", "v2/configurations/123.json",
+		&environmentSequencingRequest{SequencingEnabled: utils.Bool(true)}, &environment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, "123", *environment.ID)
+}
+
+func TestAPIClientDoRetryCount(t *testing.T) {
+	var requests int
+	client, closeServer := testAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusConflict)
+	})
+	defer closeServer()
+
+	err := client.do(context.Background(), "DELETE", "v2/configurations/123.json", nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, client.retryCount, requests)
+	if assert.IsType(t, &skytap.ErrorResponse{}, err) {
+		assert.Equal(t, http.StatusConflict, err.(*skytap.ErrorResponse).Response.StatusCode)
+	}
+}
+
+func TestAPIClientDoUnprocessableNotBusy(t *testing.T) {
+	var requests int
+	client, closeServer := testAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"error": "invalid name"}`))
+	})
+	defer closeServer()
+
+	err := client.do(context.Background(), "PUT", "v2/configurations/123.json", nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestAPIClientList(t *testing.T) {
+	var offsets []string
+	client, closeServer := testAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/v2/configurations", r.URL.Path)
+		assert.Equal(t, strconv.Itoa(listPageSize), r.URL.Query().Get("count"))
+		assert.Equal(t, "region:US-West", r.URL.Query().Get("query"))
+
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		assert.NoError(t, err)
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+
+		// two full pages followed by a partial one
+		size := listPageSize
+		if offset >= 2*listPageSize {
+			size = 5
+		}
+		page := make([]skytap.Environment, size)
+		for i := range page {
+			page[i].ID = utils.String(strconv.Itoa(offset + i))
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(page))
+	})
+	defer closeServer()
+
+	var ids []string
+	err := client.list(context.Background(), "v2/configurations", []skytap.ListFilter{
+		{Name: utils.String("region"), Value: utils.String("US-West")},
+	}, func(data json.RawMessage) error {
+		var environment skytap.Environment
+		if err := json.Unmarshal(data, &environment); err != nil {
+			return err
+		}
+		ids = append(ids, *environment.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0", "100", "200"}, offsets)
+	assert.Len(t, ids, 2*listPageSize+5)
+	assert.Equal(t, "204", ids[len(ids)-1])
+}
+
+func TestListPath(t *testing.T) {
+	assert.Equal(t, "v2/templates?count=100&offset=200", listPath("v2/templates", 100, 200, nil))
+	assert.Equal(t, "v2/templates?count=10&offset=0&query=name%3AUbuntu&scope=company",
+		listPath("v2/templates?scope=company", 10, 0, nameListFilters("Ubuntu")))
+}
+
+func TestRegexNameListFilters(t *testing.T) {
+	cases := map[string]string{
+		"Ubuntu":               "Ubuntu",
+		"^Ubuntu 18$":          "Ubuntu 18",
+		"Ubuntu 18.04.1 LTS":   "Ubuntu 18",
+		"^tftest-[0-9]+-linux": "tftest-",
+		"(?i)ubuntu":           "",
+		"ubuntu|centos":        "",
+		"name:with,separators": "",
+		".*":                   "",
+	}
+	for pattern, expected := range cases {
+		filters := regexNameListFilters(pattern)
+		if expected == "" {
+			assert.Empty(t, filters, pattern)
+			continue
+		}
+		if assert.Len(t, filters, 1, pattern) {
+			assert.Equal(t, "name", *filters[0].Name)
+			assert.Equal(t, expected, *filters[0].Value, pattern)
+		}
+	}
+}
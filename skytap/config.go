@@ -3,6 +3,7 @@ package skytap
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/skytap/skytap-sdk-go/skytap"
 )
@@ -11,6 +12,7 @@ import (
 type Config struct {
 	Username string
 	APIToken string
+	BaseURL  string
 }
 
 var maxInt = 1<<31 - 1
@@ -26,6 +28,7 @@ type SkytapClient struct {
 	publishedServicesClient skytap.PublishedServicesService
 	labelCategoryClient     skytap.LabelCategoryService
 	icnrTunnelClient        skytap.ICNRTunnelService
+	apiClient               *apiClient
 }
 
 // Client creates a SkytapClient client
@@ -45,6 +48,7 @@ func (c *Config) Client() (*SkytapClient, error) {
 		publishedServicesClient: client.PublishedServices,
 		labelCategoryClient:     client.LabelCategory,
 		icnrTunnelClient:        client.ICNRTunnel,
+		apiClient:               newAPIClient(client, maxInt),
 	}
 
 	return &skytapClient, nil
@@ -63,10 +67,21 @@ func (c *Config) createClient() (*skytap.Client, error) {
 		return nil, err
 	}
 
-	return skytap.NewClient(skytap.NewDefaultSettings(
+	settings := []skytap.ClientSetting{
 		skytap.WithCredentialsProvider(credentialsProvider),
 		skytap.WithUserAgent(userAgent),
-		skytap.WithMaxRetryCount(maxInt)))
+		skytap.WithMaxRetryCount(maxInt),
+	}
+	if c.BaseURL != "" {
+		// the API paths are resolved against the base URL, which drops its last path segment unless it ends with a slash
+		baseURL := c.BaseURL
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		settings = append(settings, skytap.WithBaseURL(baseURL))
+	}
+
+	return skytap.NewClient(skytap.NewDefaultSettings(settings...))
 }
 
 func getUserAgent() (string, error) {
@@ -1,9 +1,11 @@
 package skytap
 
 import (
+	"net/url"
 	"regexp"
 	"testing"
 
+	"github.com/skytap/skytap-sdk-go/skytap"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,3 +25,30 @@ func TestUserAgent(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, userAgent, client.UserAgent)
 }
+
+func TestBaseURL(t *testing.T) {
+	config := &Config{
+		APIToken: "abc123",
+	}
+
+	client, err := config.createClient()
+
+	assert.NoError(t, err)
+	assert.Equal(t, skytap.DefaultBaseURL, client.BaseURL.String())
+
+	config.BaseURL = "http://localhost:8080/"
+
+	client, err = config.createClient()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/", client.BaseURL.String())
+
+	config.BaseURL = "https://gw.example.com/skytap"
+
+	client, err = config.createClient()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://gw.example.com/skytap/", client.BaseURL.String())
+	rel, _ := url.Parse("v2/configurations/123.json")
+	assert.Equal(t, "https://gw.example.com/skytap/v2/configurations/123.json", client.BaseURL.ResolveReference(rel).String())
+}
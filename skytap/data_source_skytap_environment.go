@@ -0,0 +1,542 @@
+package skytap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/skytap/skytap-sdk-go/skytap"
+)
+
+func dataSourceSkytapEnvironment() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSkytapEnvironmentRead,
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Description:   "ID of the environment",
+				ValidateFunc:  validation.NoZeroValues,
+				ConflictsWith: []string{"name", "tags", "label", "most_recent"},
+			},
+
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "A regex expression for the name of the environment",
+				ValidateFunc: validation.StringIsValidRegExp,
+				AtLeastOneOf: []string{"id", "name", "tags", "label"},
+			},
+
+			"tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Description: "Set of tags the environment must have",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Set: stringCaseSensitiveHash,
+			},
+
+			"label": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Description: "Set of labels the environment must have",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"category": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Label category that provides contextual meaning",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Label value used for reporting",
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"most_recent": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Use the most recently created environment from the matching environments",
+			},
+
+			// computed attributes
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "User-defined description of the environment",
+			},
+
+			"runstate": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The runstate of the environment",
+			},
+
+			"user_data": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Environment user data, available from the metadata server and the Skytap API",
+			},
+
+			"region": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The Skytap region the environment is located in",
+			},
+
+			"owner": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the user who owns the environment",
+			},
+
+			"owner_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the user who owns the environment",
+			},
+
+			"networks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the networks in the environment",
+				Elem:        dataSourceSkytapNetworkSchema(),
+			},
+
+			"vms": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the VMs in the environment",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the VM",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VM",
+						},
+						"runstate": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The runstate of the VM",
+						},
+						"network_interface": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "List of the network interfaces of the VM",
+							Elem:        dataSourceSkytapNetworkInterfaceSchema(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceSkytapEnvironmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).environmentsClient
+
+	log.Printf("[INFO] preparing arguments for finding the Skytap Environment")
+
+	var environment *skytap.Environment
+	if v, ok := d.GetOk("id"); ok {
+		id := v.(string)
+		var err error
+		environment, err = client.Get(ctx, id)
+		if err != nil {
+			return diag.Errorf("error retrieving environment (%s): %v", id, err)
+		}
+	} else {
+		var err error
+		environment, err = findEnvironment(ctx, d, meta)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if environment.ID == nil {
+		return diag.Errorf("environment ID is not set")
+	}
+	d.SetId(*environment.ID)
+
+	log.Printf("[INFO] environment found: %s", d.Id())
+	log.Printf("[TRACE] environment found: %v", spew.Sdump(environment))
+
+	err := d.Set("name", environment.Name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("description", environment.Description)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if environment.Runstate != nil {
+		err = d.Set("runstate", string(*environment.Runstate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	err = d.Set("user_data", environment.UserData)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("region", environment.Region)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("owner", environment.OwnerID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("owner_name", environment.OwnerName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("tags", flattenTags(environment.Tags)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("label", flattenLabels(environment.Labels)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("networks", flattenDataSourceEnvironmentNetworks(environment.Networks)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vms", flattenDataSourceEnvironmentVMs(environment.VMs)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// findEnvironment looks up the single environment matching the `name`, `tags` and `label` arguments
+func findEnvironment(ctx context.Context, d *schema.ResourceData, meta interface{}) (*skytap.Environment, error) {
+	client := meta.(*SkytapClient).environmentsClient
+
+	name := d.Get("name").(string)
+
+	environments, err := listEnvironments(ctx, meta, regexNameListFilters(name))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving environments: %v", err)
+	}
+
+	if name != "" {
+		environments = filterDataSourceSkytapEnvironmentsByName(environments, name)
+	}
+	if v, ok := d.GetOk("tags"); ok {
+		environments = filterDataSourceSkytapEnvironmentsByTags(environments, v.(*schema.Set))
+	}
+
+	if v, ok := d.GetOk("label"); ok {
+		candidates, err := getEnvironments(ctx, meta, environments)
+		if err != nil {
+			return nil, err
+		}
+		environments = filterDataSourceSkytapEnvironmentsByLabels(candidates, v.(*schema.Set))
+	}
+
+	if len(environments) == 0 {
+		return nil, fmt.Errorf("no environment found matching the criteria")
+	}
+
+	environment := environments[0]
+	if len(environments) > 1 {
+		recent := d.Get("most_recent").(bool)
+		log.Printf("[DEBUG] environment datasource - multiple results found and `most_recent` is set to: %t", recent)
+		if !recent {
+			return nil, fmt.Errorf("your query returned more than one result. Please try a more " +
+				"specific search criteria, or set `most_recent` attribute to true")
+		}
+		environment = mostRecentEnvironment(environments)
+	}
+
+	// Retrieve the full environment, including its user data and labels
+	return client.Get(ctx, *environment.ID)
+}
+
+// listEnvironments retrieves every environment matching the filters, page by page
+func listEnvironments(ctx context.Context, meta interface{}, filters []skytap.ListFilter) ([]skytap.Environment, error) {
+	client := meta.(*SkytapClient).apiClient
+
+	environments := make([]skytap.Environment, 0)
+	err := client.list(ctx, "v2/configurations", filters, func(data json.RawMessage) error {
+		var environment skytap.Environment
+		if err := json.Unmarshal(data, &environment); err != nil {
+			return err
+		}
+		environments = append(environments, environment)
+		return nil
+	})
+	return environments, err
+}
+
+// getEnvironments retrieves each of the listed environments, as labels are only returned when retrieving a single environment
+func getEnvironments(ctx context.Context, meta interface{}, environments []skytap.Environment) ([]skytap.Environment, error) {
+	client := meta.(*SkytapClient).environmentsClient
+
+	result := make([]skytap.Environment, 0, len(environments))
+	for _, e := range environments {
+		environment, err := client.Get(ctx, *e.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving environment (%s): %v", *e.ID, err)
+		}
+		result = append(result, *environment)
+	}
+	return result, nil
+}
+
+func filterDataSourceSkytapEnvironmentsByName(environments []skytap.Environment, name string) []skytap.Environment {
+	var result []skytap.Environment
+	re := regexp.MustCompile(name)
+	for _, e := range environments {
+		if e.Name != nil && re.FindString(*e.Name) != "" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// filterDataSourceSkytapEnvironmentsByTags keeps the environments which have all the tags, ignoring case
+func filterDataSourceSkytapEnvironmentsByTags(environments []skytap.Environment, tags *schema.Set) []skytap.Environment {
+	var result []skytap.Environment
+	for _, e := range environments {
+		if hasAllTags(e.Tags, tags) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// filterDataSourceSkytapEnvironmentsByLabels keeps the environments which have all the labels
+func filterDataSourceSkytapEnvironmentsByLabels(environments []skytap.Environment, labels *schema.Set) []skytap.Environment {
+	var result []skytap.Environment
+	for _, e := range environments {
+		if hasAllLabels(e.Labels, labels) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// hasAllTags checks the tags contain every tag of the set, ignoring case
+func hasAllTags(tags []skytap.Tag, required *schema.Set) bool {
+	values := make(map[string]bool)
+	for _, t := range tags {
+		if t.Value != nil {
+			values[strings.ToLower(*t.Value)] = true
+		}
+	}
+	for _, t := range required.List() {
+		if !values[strings.ToLower(t.(string))] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAllLabels checks the labels contain every `label` block of the set
+func hasAllLabels(labels []*skytap.Label, required *schema.Set) bool {
+	for _, l := range required.List() {
+		label := l.(map[string]interface{})
+		if !hasLabel(labels, label["category"].(string), label["value"].(string)) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasLabel(labels []*skytap.Label, category string, value string) bool {
+	for _, l := range labels {
+		if l.LabelCategory != nil && l.Value != nil && *l.LabelCategory == category && *l.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func mostRecentEnvironment(environments []skytap.Environment) skytap.Environment {
+	sort.Slice(environments, func(i, j int) bool {
+		var time1, time2 time.Time
+		if environments[i].CreatedAt != nil {
+			time1, _ = time.Parse(timestampFormat, *environments[i].CreatedAt)
+		}
+		if environments[j].CreatedAt != nil {
+			time2, _ = time.Parse(timestampFormat, *environments[j].CreatedAt)
+		}
+		return time1.After(time2)
+	})
+	return environments[0]
+}
+
+func flattenDataSourceEnvironmentNetworks(networks []skytap.Network) []interface{} {
+	flattened := make([]interface{}, len(networks))
+	for i, v := range networks {
+		network := flattenEnvironmentNetworks([]skytap.Network{v})[0].(map[string]interface{})
+		if v.NetworkType != nil {
+			network["network_type"] = string(*v.NetworkType)
+		}
+		if v.Domain != nil {
+			network["domain"] = *v.Domain
+		}
+		if v.Gateway != nil {
+			network["gateway"] = *v.Gateway
+		}
+		if v.Tunnelable != nil {
+			network["tunnelable"] = *v.Tunnelable
+		}
+		flattened[i] = network
+	}
+	return flattened
+}
+
+func flattenDataSourceEnvironmentVMs(vms []skytap.VM) []interface{} {
+	flattened := make([]interface{}, len(vms))
+	for i, v := range vms {
+		vm := flattenEnvironmentVMs([]skytap.VM{v})[0].(map[string]interface{})
+		if v.Runstate != nil {
+			vm["runstate"] = string(*v.Runstate)
+		}
+		vm["network_interface"] = flattenNetworkInterfaces(v.Interfaces)
+		flattened[i] = vm
+	}
+	return flattened
+}
+
+func dataSourceSkytapNetworkSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the network",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the network",
+			},
+			"network_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of the network",
+			},
+			"domain": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The domain name of the network",
+			},
+			"subnet": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The subnet of the network",
+			},
+			"gateway": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The gateway IP address of the network",
+			},
+			"tunnelable": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Indicates whether the network can be connected to networks in other environments",
+			},
+		},
+	}
+}
+
+func dataSourceSkytapNetworkInterfaceSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the network interface",
+			},
+			"interface_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of the network adapter",
+			},
+			"network_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the network that the network adapter is attached to",
+			},
+			"ip": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The IP address of the network interface",
+			},
+			"hostname": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The hostname of the network interface",
+			},
+			"secondary_ips": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The secondary IP addresses of the network interface",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"published_service": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the published services of the network interface",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the published service",
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"internal_port": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The port that is exposed on the interface",
+						},
+						"external_ip": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The published service's external IP",
+						},
+						"external_port": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The published service's external port",
+						},
+					},
+				},
+			},
+		},
+	}
+}
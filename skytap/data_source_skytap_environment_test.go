@@ -0,0 +1,100 @@
+package skytap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func TestAccDataSourceSkytapEnvironment_Basic(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+	uniqueSuffix := acctest.RandInt()
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceSkytapEnvironmentConfig_basic(templateID, uniqueSuffix),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.skytap_environment.by_id", "id", "skytap_environment.foo", "id"),
+					resource.TestCheckResourceAttrPair("data.skytap_environment.by_id", "name", "skytap_environment.foo", "name"),
+					resource.TestCheckResourceAttrPair("data.skytap_environment.by_id", "user_data", "skytap_environment.foo", "user_data"),
+					resource.TestCheckResourceAttrPair("data.skytap_environment.by_id", "vms.#", "skytap_environment.foo", "vm_count"),
+					resource.TestCheckResourceAttrPair("data.skytap_environment.by_id", "networks.#", "skytap_environment.foo", "network_count"),
+					resource.TestCheckResourceAttrSet("data.skytap_environment.by_id", "runstate"),
+					resource.TestCheckResourceAttrPair("data.skytap_environment.by_name", "id", "skytap_environment.foo", "id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceSkytapEnvironmentConfig_basic(templateID string, uniqueSuffix int) string {
+	return fmt.Sprintf(`
+resource "skytap_environment" "foo" {
+	template_id = "%s"
+	name        = "tftest-environment-data-%d"
+	description = "This is an environment created by the skytap terraform provider acceptance test"
+	tags        = ["tftest-data-%d"]
+	user_data   = "environment data"
+}
+
+data "skytap_environment" "by_id" {
+	id = skytap_environment.foo.id
+}
+
+data "skytap_environment" "by_name" {
+	name = "^${skytap_environment.foo.name}$"
+	tags = ["tftest-data-%d"]
+}`, templateID, uniqueSuffix, uniqueSuffix, uniqueSuffix)
+}
+
+func TestFilterDataSourceSkytapEnvironments(t *testing.T) {
+	environments := []skytap.Environment{
+		{
+			ID:        utils.String("1"),
+			Name:      utils.String("shared-database"),
+			CreatedAt: utils.String("2021/06/01 10:00:00 -0700"),
+			Tags:      []skytap.Tag{{Value: utils.String("Shared")}},
+			Labels:    []*skytap.Label{{LabelCategory: utils.String("team"), Value: utils.String("data")}},
+		},
+		{
+			ID:        utils.String("2"),
+			Name:      utils.String("shared-app"),
+			CreatedAt: utils.String("2021/06/02 10:00:00 -0700"),
+			Tags:      []skytap.Tag{{Value: utils.String("shared")}, {Value: utils.String("app")}},
+		},
+		{
+			ID:   utils.String("3"),
+			Name: utils.String("personal"),
+		},
+	}
+
+	byName := filterDataSourceSkytapEnvironmentsByName(environments, "^shared-")
+	assert.Len(t, byName, 2)
+
+	byTags := filterDataSourceSkytapEnvironmentsByTags(environments, schema.NewSet(stringCaseSensitiveHash, []interface{}{"SHARED"}))
+	assert.Len(t, byTags, 2)
+	byTags = filterDataSourceSkytapEnvironmentsByTags(environments, schema.NewSet(stringCaseSensitiveHash, []interface{}{"shared", "app"}))
+	assert.Len(t, byTags, 1)
+	assert.Equal(t, "2", *byTags[0].ID)
+
+	labels := dataSourceSkytapEnvironment().Schema["label"]
+	labelSet := schema.NewSet(schema.HashResource(labels.Elem.(*schema.Resource)), []interface{}{
+		map[string]interface{}{"category": "team", "value": "data", "id": ""},
+	})
+	byLabels := filterDataSourceSkytapEnvironmentsByLabels(environments, labelSet)
+	assert.Len(t, byLabels, 1)
+	assert.Equal(t, "1", *byLabels[0].ID)
+
+	assert.Equal(t, "2", *mostRecentEnvironment(byName).ID)
+}
@@ -0,0 +1,269 @@
+package skytap
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/skytap/skytap-sdk-go/skytap"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/hashcode"
+)
+
+func dataSourceSkytapEnvironments() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSkytapEnvironmentsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "A regex expression for the name of the environments",
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+
+			"tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Set of tags the environments must have",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Set: stringCaseSensitiveHash,
+			},
+
+			"label": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Set of labels the environments must have",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"category": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Label category that provides contextual meaning",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Label value used for reporting",
+						},
+					},
+				},
+			},
+
+			"region": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The Skytap region the environments are located in",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"owner": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "ID of the user who owns the environments",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"runstate": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The runstate of the environments",
+				ValidateFunc: validateEnvironmentRunstate(),
+			},
+
+			// computed attributes
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the IDs of the matching environments",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"environments": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the matching environments",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the environment",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the environment",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User-defined description of the environment",
+						},
+						"runstate": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The runstate of the environment",
+						},
+						"region": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The Skytap region the environment is located in",
+						},
+						"owner": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the user who owns the environment",
+						},
+						"owner_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the user who owns the environment",
+						},
+						"vm_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of VMs in the environment",
+						},
+						"network_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of networks in the environment",
+						},
+						"created_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The date and time the environment was created",
+						},
+						"last_run": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The date and time the environment was last run",
+						},
+						"tags": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "List of the tags of the environment",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceSkytapEnvironmentsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Printf("[INFO] preparing arguments for finding the Skytap Environments")
+
+	name := d.Get("name").(string)
+
+	// The API only filters by a literal part of the name, so the regex and the other arguments are applied here
+	environments, err := listEnvironments(ctx, meta, regexNameListFilters(name))
+	if err != nil {
+		return diag.Errorf("error retrieving environments: %v", err)
+	}
+
+	if name != "" {
+		environments = filterDataSourceSkytapEnvironmentsByName(environments, name)
+	}
+	if v, ok := d.GetOk("tags"); ok {
+		environments = filterDataSourceSkytapEnvironmentsByTags(environments, v.(*schema.Set))
+	}
+	environments = filterDataSourceSkytapEnvironmentsBySummary(environments,
+		d.Get("region").(string), d.Get("owner").(string), d.Get("runstate").(string))
+	if v, ok := d.GetOk("label"); ok {
+		candidates, err := getEnvironments(ctx, meta, environments)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		environments = filterDataSourceSkytapEnvironmentsByLabels(candidates, v.(*schema.Set))
+	}
+
+	log.Printf("[INFO] environments found: %d", len(environments))
+
+	ids := make([]string, len(environments))
+	for i, e := range environments {
+		ids[i] = *e.ID
+	}
+	d.SetId(strconv.Itoa(hashcode.String(strings.Join(ids, ","))))
+
+	if err = d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("environments", flattenEnvironmentSummaries(environments)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// filterDataSourceSkytapEnvironmentsBySummary keeps the environments matching the region, owner and runstate which are set.
+// The region is compared ignoring case.
+func filterDataSourceSkytapEnvironmentsBySummary(environments []skytap.Environment, region string, owner string, runstate string) []skytap.Environment {
+	result := make([]skytap.Environment, 0)
+	for _, e := range environments {
+		if region != "" && (e.Region == nil || !strings.EqualFold(*e.Region, region)) {
+			continue
+		}
+		if owner != "" && (e.OwnerID == nil || *e.OwnerID != owner) {
+			continue
+		}
+		if runstate != "" && (e.Runstate == nil || string(*e.Runstate) != runstate) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+func flattenEnvironmentSummaries(environments []skytap.Environment) []interface{} {
+	flattened := make([]interface{}, len(environments))
+	for i, v := range environments {
+		environment := make(map[string]interface{})
+		environment["id"] = *v.ID
+		if v.Name != nil {
+			environment["name"] = *v.Name
+		}
+		if v.Description != nil {
+			environment["description"] = *v.Description
+		}
+		if v.Runstate != nil {
+			environment["runstate"] = string(*v.Runstate)
+		}
+		if v.Region != nil {
+			environment["region"] = *v.Region
+		}
+		if v.OwnerID != nil {
+			environment["owner"] = *v.OwnerID
+		}
+		if v.OwnerName != nil {
+			environment["owner_name"] = *v.OwnerName
+		}
+		if v.VMCount != nil {
+			environment["vm_count"] = *v.VMCount
+		}
+		if v.NetworkCount != nil {
+			environment["network_count"] = *v.NetworkCount
+		}
+		if v.CreatedAt != nil {
+			environment["created_at"] = *v.CreatedAt
+		}
+		if v.LastRun != nil {
+			environment["last_run"] = *v.LastRun
+		}
+		environment["tags"] = flattenTags(v.Tags)
+		flattened[i] = environment
+	}
+	return flattened
+}
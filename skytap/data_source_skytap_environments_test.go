@@ -0,0 +1,81 @@
+package skytap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func TestAccDataSourceSkytapEnvironments_Basic(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+	uniqueSuffix := acctest.RandInt()
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceSkytapEnvironmentsConfig_basic(templateID, uniqueSuffix),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.skytap_environments.tagged", "ids.#", "2"),
+					resource.TestCheckResourceAttr("data.skytap_environments.tagged", "environments.#", "2"),
+					resource.TestCheckResourceAttr("data.skytap_environments.by_name", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.skytap_environments.by_name", "ids.0", "skytap_environment.foo.0", "id"),
+					resource.TestCheckResourceAttrPair("data.skytap_environments.by_name", "environments.0.name", "skytap_environment.foo.0", "name"),
+					resource.TestCheckResourceAttrPair("data.skytap_environments.by_name", "environments.0.region", "skytap_environment.foo.0", "region"),
+					resource.TestCheckResourceAttrPair("data.skytap_environments.by_name", "environments.0.owner_name", "skytap_environment.foo.0", "owner_name"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceSkytapEnvironmentsConfig_basic(templateID string, uniqueSuffix int) string {
+	return fmt.Sprintf(`
+resource "skytap_environment" "foo" {
+	count       = 2
+	template_id = "%s"
+	name        = "tftest-environments-data-%d-${count.index}"
+	description = "This is an environment created by the skytap terraform provider acceptance test"
+	tags        = ["tftest-data-%d"]
+}
+
+data "skytap_environments" "tagged" {
+	tags = ["tftest-data-%d"]
+
+	depends_on = [skytap_environment.foo]
+}
+
+data "skytap_environments" "by_name" {
+	name = "^${skytap_environment.foo[0].name}$"
+}`, templateID, uniqueSuffix, uniqueSuffix, uniqueSuffix)
+}
+
+func TestFilterDataSourceSkytapEnvironmentsBySummary(t *testing.T) {
+	running := skytap.EnvironmentRunstateRunning
+	stopped := skytap.EnvironmentRunstateStopped
+	environments := []skytap.Environment{
+		{ID: utils.String("1"), Region: utils.String("US-West"), OwnerID: utils.String("10"), Runstate: &running},
+		{ID: utils.String("2"), Region: utils.String("US-West"), OwnerID: utils.String("20"), Runstate: &stopped},
+		{ID: utils.String("3"), Region: utils.String("EMEA"), OwnerID: utils.String("10"), Runstate: &running},
+	}
+
+	assert.Len(t, filterDataSourceSkytapEnvironmentsBySummary(environments, "", "", ""), 3)
+	assert.Len(t, filterDataSourceSkytapEnvironmentsBySummary(environments, "us-west", "", ""), 2)
+	assert.Len(t, filterDataSourceSkytapEnvironmentsBySummary(environments, "", "10", ""), 2)
+
+	filtered := filterDataSourceSkytapEnvironmentsBySummary(environments, "US-West", "", "stopped")
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "2", *filtered[0].ID)
+
+	flattened := flattenEnvironmentSummaries(filtered)
+	assert.Equal(t, "2", flattened[0].(map[string]interface{})["id"])
+	assert.Equal(t, "stopped", flattened[0].(map[string]interface{})["runstate"])
+}
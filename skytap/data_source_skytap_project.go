@@ -2,6 +2,8 @@ package skytap
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"strconv"
 
@@ -55,18 +57,16 @@ func dataSourceSkytapProject() *schema.Resource {
 }
 
 func dataSourceSkytapProjectRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*SkytapClient).projectsClient
-
 	log.Printf("[INFO] preparing arguments for finding the Skytap Project")
 
 	name := d.Get("name").(string)
 
-	projectsResult, err := client.List(ctx)
+	projectsResult, err := listProjects(ctx, meta, nameListFilters(name))
 	if err != nil {
 		return diag.Errorf("error retrieving projects: %s", err)
 	}
 
-	projects := filterDataSourceSkytapProjectsByName(projectsResult.Value, name)
+	projects := filterDataSourceSkytapProjectsByName(projectsResult, name)
 
 	if len(projects) == 0 {
 		return diag.Errorf("no project found with name %s", name)
@@ -100,11 +100,11 @@ func dataSourceSkytapProjectRead(ctx context.Context, d *schema.ResourceData, me
 		return diag.FromErr(err)
 	}
 
-	environments, err := client.ListEnvironments(ctx, *project.ID)
+	environments, err := listProjectEnvironments(ctx, meta, *project.ID)
 	if err != nil {
 		return diag.Errorf("error retrieving project environments: %v", err)
 	}
-	err = d.Set("environment_ids", flattenProjectEnvironments(environments.Value))
+	err = d.Set("environment_ids", flattenProjectEnvironments(environments))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -112,6 +112,38 @@ func dataSourceSkytapProjectRead(ctx context.Context, d *schema.ResourceData, me
 	return nil
 }
 
+// listProjects retrieves every project matching the filters, page by page
+func listProjects(ctx context.Context, meta interface{}, filters []skytap.ListFilter) ([]skytap.Project, error) {
+	client := meta.(*SkytapClient).apiClient
+
+	projects := make([]skytap.Project, 0)
+	err := client.list(ctx, "v2/projects", filters, func(data json.RawMessage) error {
+		var project skytap.Project
+		if err := json.Unmarshal(data, &project); err != nil {
+			return err
+		}
+		projects = append(projects, project)
+		return nil
+	})
+	return projects, err
+}
+
+// listProjectEnvironments retrieves every environment within the project, page by page
+func listProjectEnvironments(ctx context.Context, meta interface{}, id int) ([]skytap.ProjectEnvironment, error) {
+	client := meta.(*SkytapClient).apiClient
+
+	environments := make([]skytap.ProjectEnvironment, 0)
+	err := client.list(ctx, fmt.Sprintf("v2/projects/%d/configurations", id), nil, func(data json.RawMessage) error {
+		var environment skytap.ProjectEnvironment
+		if err := json.Unmarshal(data, &environment); err != nil {
+			return err
+		}
+		environments = append(environments, environment)
+		return nil
+	})
+	return environments, err
+}
+
 func filterDataSourceSkytapProjectsByName(projects []skytap.Project, name string) []skytap.Project {
 	var result []skytap.Project
 	for _, p := range projects {
@@ -2,11 +2,16 @@ package skytap
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
+	"net/url"
 	"regexp"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -20,17 +25,241 @@ func dataSourceSkytapTemplate() *schema.Resource {
 		ReadContext: dataSourceSkytapTemplateRead,
 
 		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Description:   "ID of the template",
+				ValidateFunc:  validation.NoZeroValues,
+				ConflictsWith: []string{"name", "tags", "label", "region", "public", "scope", "most_recent"},
+			},
 			"name": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
+				Computed:     true,
 				Description:  "A regex expression for the name of the template",
+				ValidateFunc: validation.StringIsValidRegExp,
+				AtLeastOneOf: []string{"id", "name", "tags", "label"},
+			},
+			"tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Description: "Set of tags the template must have",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Set: stringCaseSensitiveHash,
+			},
+			"label": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Description: "Set of labels the template must have",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"category": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Label category that provides contextual meaning",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Label value used for reporting",
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"region": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The Skytap region the template is located in",
 				ValidateFunc: validation.NoZeroValues,
 			},
+			"public": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the template is a public template provided by Skytap",
+			},
+			"scope": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Search the templates of the user (`me`) or of the whole company (`company`). Defaults to the templates visible to the user",
+				ValidateFunc: validation.StringInSlice([]string{"me", "company"}, false),
+			},
 			"most_recent": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Description: "Use the most recently created template from the returned list",
 			},
+
+			// computed attributes
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "User-defined description of the template",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date and time the template was created",
+			},
+			"vm_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of VMs in the template",
+			},
+			"network_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of networks in the template",
+			},
+			"storage": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The total storage of the VMs in the template, in MiB",
+			},
+			"svms": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of Skytap Virtual Machine units used by the template",
+			},
+			"networks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the networks in the template",
+				Elem:        dataSourceSkytapNetworkSchema(),
+			},
+			"vms": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the VMs in the template",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the VM, to be used as the `vm_id` of a `skytap_vm`",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VM",
+						},
+						"hardware": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The hardware of the VM",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"cpus": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Number of CPUs allocated to the VM",
+									},
+									"cpus_per_socket": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Number of CPUs per socket",
+									},
+									"max_cpus": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The maximum number of CPUs the VM can be allocated",
+									},
+									"ram": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "Amount of RAM allocated to the VM, in MiB",
+									},
+									"min_ram": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The minimum amount of RAM the VM can be allocated, in MiB",
+									},
+									"max_ram": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The maximum amount of RAM the VM can be allocated, in MiB",
+									},
+									"svms": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The number of Skytap Virtual Machine units used by the VM",
+									},
+									"storage": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The total storage of the VM, in MiB",
+									},
+									"guest_os": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The operating system of the VM",
+									},
+									"architecture": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The architecture of the VM",
+									},
+								},
+							},
+						},
+						"disk": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "List of the virtual disks of the VM, including the operating system disk",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "ID of the disk",
+									},
+									"name": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Name of the disk",
+									},
+									"size": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The size of the disk, in MiB",
+									},
+									"type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The type of disk",
+									},
+									"controller": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The disk controller",
+									},
+									"lun": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The logical unit number (LUN) of the disk",
+									},
+								},
+							},
+						},
+						"network_interface": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "List of the network interfaces of the VM",
+							Elem:        dataSourceSkytapNetworkInterfaceSchema(),
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -40,42 +269,157 @@ func dataSourceSkytapTemplateRead(ctx context.Context, d *schema.ResourceData, m
 
 	log.Printf("[INFO] preparing arguments for finding the Skytap Template")
 
+	id := d.Get("id").(string)
+	if id == "" {
+		template, err := findTemplate(ctx, d, meta)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if template.ID == nil {
+			return diag.Errorf("template ID is not set")
+		}
+		id = *template.ID
+	}
+
+	// Retrieve the full template, including its VMs and networks
+	template, err := client.Get(ctx, id)
+	if err != nil {
+		return diag.Errorf("error retrieving template (%s): %v", id, err)
+	}
+	labels, err := getTemplateLabels(ctx, meta, id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(id)
+
+	log.Printf("[INFO] template found: %s", d.Id())
+	log.Printf("[TRACE] template found: %v", spew.Sdump(template))
+
+	err = d.Set("name", template.Name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("description", template.Description)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("region", template.Region)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("public", template.Public)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("created_at", template.CreatedAt)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("vm_count", template.VMCount)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("network_count", template.NetworkCount)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("storage", template.Storage)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("svms", template.SVMs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("tags", flattenTags(template.Tags)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("label", flattenLabels(labels)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("networks", flattenDataSourceEnvironmentNetworks(template.Networks)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vms", flattenDataSourceTemplateVMs(template.VMs)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// findTemplate looks up the single template matching the `name`, `tags`, `label`, `region` and `public` arguments
+func findTemplate(ctx context.Context, d *schema.ResourceData, meta interface{}) (*skytap.Template, error) {
 	name := d.Get("name").(string)
 
-	templatesResult, err := client.List(ctx)
+	templates, err := listTemplates(ctx, meta, d.Get("scope").(string), regexNameListFilters(name))
 	if err != nil {
-		return diag.Errorf("error retrieving templates: %s", err)
+		return nil, fmt.Errorf("error retrieving templates: %s", err)
 	}
 
-	templates := filterDataSourceSkytapTemplatesByName(templatesResult.Value, name)
+	if name != "" {
+		templates = filterDataSourceSkytapTemplatesByName(templates, name)
+	}
+	var public *bool
+	if v, ok := d.GetOkExists("public"); ok {
+		b := v.(bool)
+		public = &b
+	}
+	templates = filterDataSourceSkytapTemplates(templates, d.Get("tags").(*schema.Set), d.Get("region").(string), public)
 
-	if len(templates) == 0 {
-		return diag.Errorf("no template found with name %s", name)
+	// Labels are only returned by their own endpoint
+	if v, ok := d.GetOk("label"); ok {
+		candidates := make([]skytap.Template, 0)
+		for _, t := range templates {
+			labels, err := getTemplateLabels(ctx, meta, *t.ID)
+			if err != nil {
+				return nil, err
+			}
+			if hasAllLabels(labels, v.(*schema.Set)) {
+				candidates = append(candidates, t)
+			}
+		}
+		templates = candidates
 	}
 
-	var template skytap.Template
+	if len(templates) == 0 {
+		if name != "" {
+			return nil, fmt.Errorf("no template found with name %s", name)
+		}
+		return nil, fmt.Errorf("no template found matching the criteria")
+	}
 
+	template := templates[0]
 	if len(templates) > 1 {
 		recent := d.Get("most_recent").(bool)
 		log.Printf("[DEBUG] template datasource - multiple results found and `most_recent` is set to: %t", recent)
-		if recent {
-			template = mostRecentTemplate(templates)
-		} else {
-			return diag.Errorf("your query returned more than one result. Please try a more " +
+		if !recent {
+			return nil, fmt.Errorf("your query returned more than one result. Please try a more " +
 				"specific search criteria, or set `most_recent` attribute to true")
 		}
-	} else {
-		template = templates[0]
+		template = mostRecentTemplate(templates)
 	}
+	return &template, nil
+}
+
+// listTemplates retrieves every template matching the scope and filters, page by page
+func listTemplates(ctx context.Context, meta interface{}, scope string, filters []skytap.ListFilter) ([]skytap.Template, error) {
+	client := meta.(*SkytapClient).apiClient
 
-	if template.ID == nil {
-		return diag.Errorf("template ID is not set")
+	path := "v2/templates"
+	if scope != "" {
+		path += "?scope=" + url.QueryEscape(scope)
 	}
-	templateID := *template.ID
-	d.SetId(templateID)
-	d.Set("name", template.Name)
 
-	return nil
+	templates := make([]skytap.Template, 0)
+	err := client.list(ctx, path, filters, func(data json.RawMessage) error {
+		var template skytap.Template
+		if err := json.Unmarshal(data, &template); err != nil {
+			return err
+		}
+		templates = append(templates, template)
+		return nil
+	})
+	return templates, err
 }
 
 func filterDataSourceSkytapTemplatesByName(templates []skytap.Template, name string) []skytap.Template {
@@ -89,6 +433,36 @@ func filterDataSourceSkytapTemplatesByName(templates []skytap.Template, name str
 	return result
 }
 
+// filterDataSourceSkytapTemplates keeps the templates which have all the tags, ignoring case, and match the region
+// and public flag when they are set
+func filterDataSourceSkytapTemplates(templates []skytap.Template, tags *schema.Set, region string, public *bool) []skytap.Template {
+	result := make([]skytap.Template, 0)
+	for _, t := range templates {
+		if tags != nil && !hasAllTags(t.Tags, tags) {
+			continue
+		}
+		if region != "" && (t.Region == nil || !strings.EqualFold(*t.Region, region)) {
+			continue
+		}
+		if public != nil && (t.Public == nil || *t.Public != *public) {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// getTemplateLabels retrieves the labels of the template, which are not returned with the template
+func getTemplateLabels(ctx context.Context, meta interface{}, id string) ([]*skytap.Label, error) {
+	client := meta.(*SkytapClient).apiClient
+
+	var labels []*skytap.Label
+	if err := client.do(ctx, "GET", fmt.Sprintf("v2/templates/%s/labels.json", id), nil, &labels); err != nil {
+		return nil, fmt.Errorf("error retrieving template (%s) labels: %v", id, err)
+	}
+	return labels, nil
+}
+
 func mostRecentTemplate(templates []skytap.Template) skytap.Template {
 	sort.Slice(templates, func(i, j int) bool {
 		time1, _ := time.Parse(timestampFormat, *templates[i].CreatedAt)
@@ -97,3 +471,56 @@ func mostRecentTemplate(templates []skytap.Template) skytap.Template {
 	})
 	return templates[0]
 }
+
+func flattenDataSourceTemplateVMs(vms []skytap.VM) []interface{} {
+	flattened := make([]interface{}, len(vms))
+	for i, v := range vms {
+		vm := flattenEnvironmentVMs([]skytap.VM{v})[0].(map[string]interface{})
+		if v.Hardware != nil {
+			vm["hardware"] = flattenHardware(v.Hardware)
+			disks := make([]interface{}, len(v.Hardware.Disks))
+			for j, disk := range v.Hardware.Disks {
+				disks[j] = flattenDisk(disk)
+			}
+			vm["disk"] = disks
+		}
+		vm["network_interface"] = flattenNetworkInterfaces(v.Interfaces)
+		flattened[i] = vm
+	}
+	return flattened
+}
+
+func flattenHardware(hardware *skytap.Hardware) []interface{} {
+	result := make(map[string]interface{})
+	if hardware.CPUs != nil {
+		result["cpus"] = *hardware.CPUs
+	}
+	if hardware.CpusPerSocket != nil {
+		result["cpus_per_socket"] = *hardware.CpusPerSocket
+	}
+	if hardware.MaxCPUs != nil {
+		result["max_cpus"] = *hardware.MaxCPUs
+	}
+	if hardware.RAM != nil {
+		result["ram"] = *hardware.RAM
+	}
+	if hardware.MinRAM != nil {
+		result["min_ram"] = *hardware.MinRAM
+	}
+	if hardware.MaxRAM != nil {
+		result["max_ram"] = *hardware.MaxRAM
+	}
+	if hardware.SVMs != nil {
+		result["svms"] = *hardware.SVMs
+	}
+	if hardware.Storage != nil {
+		result["storage"] = *hardware.Storage
+	}
+	if hardware.GuestOS != nil {
+		result["guest_os"] = *hardware.GuestOS
+	}
+	if hardware.Architecture != nil {
+		result["architecture"] = *hardware.Architecture
+	}
+	return []interface{}{result}
+}
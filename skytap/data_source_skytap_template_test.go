@@ -1,10 +1,14 @@
 package skytap
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
 )
@@ -67,3 +71,80 @@ output "id" {
   value = "${data.skytap_template.foo.id}"
 }`, partial)
 }
+
+func TestAccDataSourceSkytapTemplate_ID(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceSkytapTemplateConfig_id(templateID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.skytap_template.foo", "id", templateID),
+					resource.TestCheckResourceAttrSet("data.skytap_template.foo", "name"),
+					resource.TestCheckResourceAttrSet("data.skytap_template.foo", "region"),
+					resource.TestCheckResourceAttrSet("data.skytap_template.foo", "storage"),
+					resource.TestCheckResourceAttrSet("data.skytap_template.foo", "vms.0.id"),
+					resource.TestCheckResourceAttrSet("data.skytap_template.foo", "vms.0.hardware.0.max_cpus"),
+					resource.TestCheckResourceAttrSet("data.skytap_template.foo", "vms.0.disk.0.size"),
+					resource.TestCheckResourceAttrSet("data.skytap_template.foo", "networks.0.subnet"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceSkytapTemplateConfig_id(id string) string {
+	return fmt.Sprintf(`
+data "skytap_template" "foo" {
+	id = "%s"
+}`, id)
+}
+
+func TestFilterDataSourceSkytapTemplates(t *testing.T) {
+	templates := []skytap.Template{
+		{ID: utils.String("1"), Region: utils.String("US-West"), Public: utils.Bool(true), Tags: []skytap.Tag{{Value: utils.String("Ubuntu")}}},
+		{ID: utils.String("2"), Region: utils.String("US-West"), Public: utils.Bool(false)},
+		{ID: utils.String("3"), Region: utils.String("EMEA"), Public: utils.Bool(false), Tags: []skytap.Tag{{Value: utils.String("ubuntu")}}},
+	}
+	noTags := schema.NewSet(stringCaseSensitiveHash, nil)
+
+	assert.Len(t, filterDataSourceSkytapTemplates(templates, noTags, "", nil), 3)
+	assert.Len(t, filterDataSourceSkytapTemplates(templates, schema.NewSet(stringCaseSensitiveHash, []interface{}{"UBUNTU"}), "", nil), 2)
+	assert.Len(t, filterDataSourceSkytapTemplates(templates, noTags, "us-west", nil), 2)
+
+	filtered := filterDataSourceSkytapTemplates(templates, noTags, "US-West", utils.Bool(false))
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "2", *filtered[0].ID)
+}
+
+func TestFlattenDataSourceTemplateVMs(t *testing.T) {
+	var template skytap.Template
+	err := json.Unmarshal(readTestFile(t, "template_response.json"), &template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vms := flattenDataSourceTemplateVMs(template.VMs)
+	assert.Len(t, vms, 1)
+	vm := vms[0].(map[string]interface{})
+	assert.Equal(t, "37865463", vm["id"])
+
+	hardware := vm["hardware"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 12, hardware["max_cpus"])
+	assert.Equal(t, 131072, hardware["max_ram"])
+	assert.Equal(t, "ubuntu-64", hardware["guest_os"])
+
+	disks := vm["disk"].([]interface{})
+	assert.Len(t, disks, 1)
+	assert.Equal(t, 30720, disks[0].(map[string]interface{})["size"])
+
+	interfaces := vm["network_interface"].([]interface{})
+	assert.Len(t, interfaces, 1)
+	assert.Equal(t, "9876", interfaces[0].(map[string]interface{})["network_id"])
+
+	networks := flattenDataSourceEnvironmentNetworks(template.Networks)
+	assert.Equal(t, "skytap.example", networks[0].(map[string]interface{})["domain"])
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/skytap/skytap-sdk-go/skytap"
 )
 
 const (
@@ -29,20 +31,34 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("SKYTAP_API_TOKEN", nil),
 				Description: "The Skytap API token. May also be specified by the `SKYTAP_API_TOKEN` shell environment variable",
 			},
+			"base_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SKYTAP_BASE_URL", skytap.DefaultBaseURL),
+				Description:  "The base URL of the Skytap API. Defaults to `https://cloud.skytap.com/`. May also be specified by the `SKYTAP_BASE_URL` shell environment variable",
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"skytap_project":  dataSourceSkytapProject(),
-			"skytap_template": dataSourceSkytapTemplate(),
+			"skytap_project":      dataSourceSkytapProject(),
+			"skytap_template":     dataSourceSkytapTemplate(),
+			"skytap_environment":  dataSourceSkytapEnvironment(),
+			"skytap_environments": dataSourceSkytapEnvironments(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"skytap_project":        resourceSkytapProject(),
-			"skytap_environment":    resourceSkytapEnvironment(),
-			"skytap_network":        resourceSkytapNetwork(),
-			"skytap_vm":             resourceSkytapVM(),
-			"skytap_label_category": resourceSkytapLabelCategory(),
-			"skytap_icnr_tunnel":    resourceSkytapICNRTunnel(),
+			"skytap_project":              resourceSkytapProject(),
+			"skytap_environment":          resourceSkytapEnvironment(),
+			"skytap_network":              resourceSkytapNetwork(),
+			"skytap_network_interface":    resourceSkytapNetworkInterface(),
+			"skytap_published_service":    resourceSkytapPublishedService(),
+			"skytap_public_ip":            resourceSkytapPublicIP(),
+			"skytap_public_ip_attachment": resourceSkytapPublicIPAttachment(),
+			"skytap_vm":                   resourceSkytapVM(),
+			"skytap_vm_disk":              resourceSkytapVMDisk(),
+			"skytap_label_category":       resourceSkytapLabelCategory(),
+			"skytap_icnr_tunnel":          resourceSkytapICNRTunnel(),
 		},
 	}
 
@@ -53,6 +69,7 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 	config := &Config{
 		Username: d.Get("username").(string),
 		APIToken: d.Get("api_token").(string),
+		BaseURL:  d.Get("base_url").(string),
 	}
 
 	client, err := config.Client()
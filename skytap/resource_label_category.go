@@ -20,6 +20,9 @@ func resourceSkytapLabelCategory() *schema.Resource {
 		CreateContext: resourceSkytapLabelCategoryCreate,
 		ReadContext:   resourceSkytapLabelCategoryRead,
 		DeleteContext: resourceSkytapLabelCategoryDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateIntID("label category"),
+		},
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
@@ -41,6 +41,11 @@ func TestAccSkytapLabelCategory_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("skytap_label_category.env_category", "single_value", "true"),
 				),
 			},
+			{
+				ResourceName:      "skytap_label_category.env_category",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
@@ -21,6 +22,9 @@ func resourceSkytapEnvironment() *schema.Resource {
 		ReadContext:   resourceSkytapEnvironmentRead,
 		UpdateContext: resourceSkytapEnvironmentUpdate,
 		DeleteContext: resourceSkytapEnvironmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(20 * time.Minute),
@@ -30,11 +34,12 @@ func resourceSkytapEnvironment() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"template_id": {
-				Type:         schema.TypeString,
-				Required:     true,
-				Description:  "ID of the template you want to create the environment from. If updated with a new ID, the environment will be recreated",
-				ForceNew:     true,
-				ValidateFunc: validation.NoZeroValues,
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "ID of the template you want to create the environment from. If updated with a new ID, the environment will be recreated",
+				ForceNew:         true,
+				ValidateFunc:     validation.NoZeroValues,
+				DiffSuppressFunc: importedSuppress,
 			},
 
 			"name": {
@@ -51,6 +56,28 @@ func resourceSkytapEnvironment() *schema.Resource {
 				ValidateFunc: validation.NoZeroValues,
 			},
 
+			"project_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "ID of the project the environment is created in. If updated, the environment is moved from the previous project to the new one",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"project_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Set of IDs of all the projects the environment belongs to",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+
+			"owner": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "ID of the user who owns the environment. Defaults to the user creating the environment",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
 			"outbound_traffic": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -132,6 +159,236 @@ func resourceSkytapEnvironment() *schema.Resource {
 				Default:     nil,
 				Description: "The date and time that the environment will be automatically shut down. Format: yyyy/mm/dd hh:mm:ss. By default, the suspend time uses the UTC offset for the time zone defined in your user account settings. Optionally, a different UTC offset can be supplied (for example: 2018/07/20 14:20:00 -0000). The value in the API response is converted to your time zone",
 			},
+
+			"runstate": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				Description:      "The desired runstate of the environment: `running`, `suspended`, `stopped` or `halted`. A `halted` environment has its VMs shut down through the guest OS and is reported as `stopped` once complete. If not set, the environment is started after creation and its runstate is left unmanaged",
+				ValidateFunc:     validateEnvironmentRunstate(),
+				DiffSuppressFunc: environmentRunstateSuppress,
+			},
+
+			"sequencing_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Indicates whether the VMs in the environment are started and stopped in the order defined by the `stage` blocks",
+			},
+
+			"stage": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Ordered list of VM stages. When sequencing is enabled, the VMs of each stage are started in turn, and stopped in reverse order. The initial start after creation is not sequenced. The stages copied from the template are removed unless declared",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"vm_ids": {
+							Type:        schema.TypeSet,
+							Required:    true,
+							Description: "Set of IDs of the VMs in the stage",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Set:         schema.HashString,
+						},
+						"delay_after_finish_seconds": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							Description:  "The number of seconds to wait once the VMs in the stage have finished before moving to the next stage",
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
+
+			"staged_execution": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The progress of the sequenced start or stop in progress, if any",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The action being sequenced",
+						},
+						"current_stage_index": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The index of the stage being executed",
+						},
+						"current_stage_delay_after_finish_seconds": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The delay applied once the current stage has finished",
+						},
+						"current_stage_finished_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The date and time the current stage finished",
+						},
+						"vm_ids": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "List of IDs of the VMs in the current stage",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+
+			"region": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The Skytap region the environment is located in",
+			},
+
+			"vm_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of VMs in the environment",
+			},
+
+			"storage": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The total storage, in MiB, allocated to the VMs in the environment",
+			},
+
+			"svms": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of Skytap Virtual Machines (SVMs) consumed by the running VMs in the environment",
+			},
+
+			"svms_by_architecture": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The number of SVMs consumed by the running VMs in the environment, by architecture",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"x86": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of SVMs consumed by x86 VMs",
+						},
+						"power": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of SVMs consumed by Power VMs",
+						},
+					},
+				},
+			},
+
+			"network_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of networks in the environment",
+			},
+
+			"owner_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the user who owns the environment",
+			},
+
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date and time the environment was created",
+			},
+
+			"last_run": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date and time the environment was last run",
+			},
+
+			"auto_suspend_description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A description of the automatic suspend settings of the environment",
+			},
+
+			"public_ip_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of public IP addresses attached to the environment",
+			},
+
+			"alerts": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the alerts raised on the environment",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"display_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of alert",
+						},
+						"dismissable": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Indicates whether the alert can be dismissed",
+						},
+						"message": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The alert message",
+						},
+					},
+				},
+			},
+
+			"vms": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the VMs in the environment, including the VMs created from the template",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the VM",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the VM",
+						},
+					},
+				},
+			},
+
+			"networks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the networks in the environment, including the networks created from the template",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the network",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the network",
+						},
+						"subnet": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The subnet of the network",
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -147,6 +404,14 @@ func resourceSkytapEnvironmentCreate(ctx context.Context, d *schema.ResourceData
 		Name:       &name,
 	}
 
+	if v, ok := d.GetOk("project_id"); ok {
+		opts.ProjectID = utils.Int(v.(int))
+	}
+
+	if v, ok := d.GetOk("owner"); ok {
+		opts.Owner = utils.String(v.(string))
+	}
+
 	if v, ok := d.GetOk("outbound_traffic"); ok {
 		opts.OutboundTraffic = utils.Bool(v.(bool))
 	}
@@ -218,6 +483,30 @@ func resourceSkytapEnvironmentCreate(ctx context.Context, d *schema.ResourceData
 		return diag.Errorf("error waiting for environment (%s) to complete: %s", d.Id(), err)
 	}
 
+	// The stages copied from the template are cleared unless configured
+	if _, ok := d.GetOkExists("sequencing_enabled"); ok || d.Get("stage.#").(int) > 0 || len(environment.Stages) > 0 {
+		if err = updateEnvironmentSequencing(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// The environment is started after creation. Apply any other runstate requested.
+	if v, ok := d.GetOk("runstate"); ok && v.(string) != string(skytap.EnvironmentRunstateRunning) {
+		runstate := skytap.EnvironmentRunstate(v.(string))
+		opts := skytap.UpdateEnvironmentRequest{
+			Runstate: utils.EnvironmentRunstate(runstate),
+		}
+
+		log.Printf("[INFO] environment (%s) changing runstate to: %s", environmentID, runstate)
+		if _, err = client.Update(ctx, environmentID, &opts); err != nil {
+			return diag.Errorf("error changing environment (%s) runstate: %v", environmentID, err)
+		}
+
+		if err = waitForEnvironmentRunstate(ctx, d, meta, environmentID, runstate, schema.TimeoutCreate); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return resourceSkytapEnvironmentRead(ctx, d, meta)
 }
 
@@ -281,6 +570,94 @@ func resourceSkytapEnvironmentRead(ctx context.Context, d *schema.ResourceData,
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = d.Set("owner", environment.OwnerID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	projects, err := client.ListProjects(ctx, id)
+	if err != nil {
+		return diag.Errorf("error retrieving environment (%s) projects: %v", id, err)
+	}
+	if err = d.Set("project_ids", flattenProjectIDs(projects.Value)); err != nil {
+		return diag.FromErr(err)
+	}
+	// Clear the project if the environment has been removed from it, so that it is added back
+	if projectID, ok := d.GetOk("project_id"); ok && !environmentInProject(projects.Value, projectID.(int)) {
+		log.Printf("[DEBUG] environment (%s) is no longer in project (%d)", id, projectID.(int))
+		if err = d.Set("project_id", 0); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if environment.Runstate != nil {
+		err = d.Set("runstate", string(*environment.Runstate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	err = d.Set("sequencing_enabled", environment.SequencingEnabled)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("stage", flattenStages(environment.Stages)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("staged_execution", flattenStagedExecution(environment.StagedExecution)); err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("region", environment.Region)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("vm_count", environment.VMCount)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("storage", environment.Storage)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("svms", environment.SVMs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("svms_by_architecture", flattenSVMsByArchitecture(environment.SVMsByArchitecture)); err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("network_count", environment.NetworkCount)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("owner_name", environment.OwnerName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("created_at", environment.CreatedAt)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("last_run", environment.LastRun)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("auto_suspend_description", environment.AutoSuspendDescription)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("public_ip_count", environment.PublicIPCount)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("alerts", flattenAlerts(environment.Alerts)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vms", flattenEnvironmentVMs(environment.VMs)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("networks", flattenEnvironmentNetworks(environment.Networks)); err != nil {
+		return diag.FromErr(err)
+	}
 
 	if environment.Tags != nil {
 		if err = d.Set("tags", flattenTags(environment.Tags)); err != nil {
@@ -303,7 +680,7 @@ func resourceSkytapEnvironmentRead(ctx context.Context, d *schema.ResourceData,
 	log.Printf("[INFO] environment retrieved: %s", id)
 	log.Printf("[TRACE] environment retrieved: %v", spew.Sdump(environment))
 
-	return nil
+	return environmentAlertDiagnostics(environment)
 }
 
 func resourceSkytapEnvironmentUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -325,6 +702,10 @@ func resourceSkytapEnvironmentUpdate(ctx context.Context, d *schema.ResourceData
 		opts.Description = utils.String(v.(string))
 	}
 
+	if v, ok := d.GetOk("owner"); ok && d.HasChange("owner") {
+		opts.Owner = utils.String(v.(string))
+	}
+
 	if v, ok := d.GetOk("suspend_on_idle"); ok {
 		opts.SuspendOnIdle = utils.Int(v.(int))
 	}
@@ -341,6 +722,25 @@ func resourceSkytapEnvironmentUpdate(ctx context.Context, d *schema.ResourceData
 		opts.ShutdownAtTime = utils.String(v.(string))
 	}
 
+	if d.HasChange("project_id") {
+		if err := updateEnvironmentProject(ctx, d, meta, id); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// Apply the sequencing first, so that any runstate change follows the new stages
+	if d.HasChanges("sequencing_enabled", "stage") {
+		if err := updateEnvironmentSequencing(ctx, d, meta, id, schema.TimeoutUpdate); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	var runstate *skytap.EnvironmentRunstate
+	if v, ok := d.GetOk("runstate"); ok && d.HasChange("runstate") {
+		runstate = utils.EnvironmentRunstate(skytap.EnvironmentRunstate(v.(string)))
+		opts.Runstate = runstate
+	}
+
 	log.Printf("[INFO] environment update: %s", id)
 	log.Printf("[TRACE] environment update options: %v", spew.Sdump(opts))
 
@@ -351,7 +751,11 @@ func resourceSkytapEnvironmentUpdate(ctx context.Context, d *schema.ResourceData
 
 	log.Printf("[INFO] environment updated: %s", id)
 	log.Printf("[TRACE] environment updated: %v", spew.Sdump(environment))
-	if err = waitForEnvironmentReady(ctx, d, meta, *environment.ID, schema.TimeoutUpdate); err != nil {
+	if runstate != nil {
+		if err = waitForEnvironmentRunstate(ctx, d, meta, *environment.ID, *runstate, schema.TimeoutUpdate); err != nil {
+			return diag.FromErr(err)
+		}
+	} else if err = waitForEnvironmentReady(ctx, d, meta, *environment.ID, schema.TimeoutUpdate); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -410,7 +814,7 @@ func waitForEnvironmentReady(ctx context.Context, d *schema.ResourceData, meta i
 	stateConf := &resource.StateChangeConf{
 		Pending:    environmentPendingUpdateRunstates,
 		Target:     environmentTargetUpdateRunstates,
-		Refresh:    environmentUpdateRunstateRefreshFunc(ctx, meta, environmentID),
+		Refresh:    environmentUpdateRunstateRefreshFunc(ctx, meta, environmentID, false),
 		Timeout:    d.Timeout(schemaTimeout),
 		MinTimeout: minTimeout * time.Second,
 		Delay:      delay * time.Second,
@@ -424,6 +828,93 @@ func waitForEnvironmentReady(ctx context.Context, d *schema.ResourceData, meta i
 	return nil
 }
 
+// waitForEnvironmentRunstate waits until the environment settles in the requested runstate
+func waitForEnvironmentRunstate(ctx context.Context, d *schema.ResourceData, meta interface{}, environmentID string,
+	runstate skytap.EnvironmentRunstate, schemaTimeout string) error {
+	target := environmentTargetRunstates(runstate)
+	stateConf := &resource.StateChangeConf{
+		Pending:    environmentPendingRunstates(target),
+		Target:     target,
+		Refresh:    environmentUpdateRunstateRefreshFunc(ctx, meta, environmentID, true),
+		Timeout:    d.Timeout(schemaTimeout),
+		MinTimeout: minTimeout * time.Second,
+		Delay:      delay * time.Second,
+	}
+
+	log.Printf("[INFO] Waiting for environment (%s) to be %s", environmentID, runstate)
+	_, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error waiting for environment (%s) to be %s: %s", environmentID, runstate, err)
+	}
+	return nil
+}
+
+// updateEnvironmentProject moves the environment from the previous project to the one in `project_id`
+func updateEnvironmentProject(ctx context.Context, d *schema.ResourceData, meta interface{}, environmentID string) error {
+	client := meta.(*SkytapClient).projectsClient
+
+	old, new := d.GetChange("project_id")
+	if oldID := old.(int); oldID != 0 {
+		log.Printf("[INFO] removing environment (%s) from project (%d)", environmentID, oldID)
+		if err := client.RemoveEnvironment(ctx, oldID, environmentID); err != nil && !utils.ResponseErrorIsNotFound(err) {
+			return fmt.Errorf("error removing environment (%s) from project (%d): %v", environmentID, oldID, err)
+		}
+	}
+	if newID := new.(int); newID != 0 {
+		log.Printf("[INFO] adding environment (%s) to project (%d)", environmentID, newID)
+		if _, err := client.AddEnvironment(ctx, newID, environmentID); err != nil {
+			return fmt.Errorf("error adding environment (%s) to project (%d): %v", environmentID, newID, err)
+		}
+	}
+	return nil
+}
+
+func environmentInProject(projects []skytap.Project, projectID int) bool {
+	for _, project := range projects {
+		if project.ID != nil && *project.ID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// environmentSequencingRequest describes the VM sequencing of an environment. It is not supported by the SDK.
+type environmentSequencingRequest struct {
+	SequencingEnabled *bool                     `json:"sequencing_enabled,omitempty"`
+	Stages            []environmentStageRequest `json:"stages"`
+}
+
+type environmentStageRequest struct {
+	DelayAfterFinishSeconds int      `json:"delay_after_finish_seconds"`
+	VMIDs                   []string `json:"vm_ids"`
+}
+
+// updateEnvironmentSequencing applies the `sequencing_enabled` and `stage` arguments. The stages are always sent,
+// so that removing every `stage` block clears them.
+func updateEnvironmentSequencing(ctx context.Context, d *schema.ResourceData, meta interface{}, environmentID string,
+	schemaTimeout string) error {
+	client := meta.(*SkytapClient).apiClient
+
+	opts := environmentSequencingRequest{}
+	if v, ok := d.GetOkExists("sequencing_enabled"); ok {
+		opts.SequencingEnabled = utils.Bool(v.(bool))
+	}
+	opts.Stages = expandStages(d.Get("stage").([]interface{}))
+
+	if err := waitForEnvironmentReady(ctx, d, meta, environmentID, schemaTimeout); err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] environment sequencing update: %s", environmentID)
+	log.Printf("[TRACE] environment sequencing update options: %v", spew.Sdump(opts))
+	path := fmt.Sprintf("v2/configurations/%s.json", environmentID)
+	if err := client.do(ctx, "PUT", path, &opts, nil); err != nil {
+		return fmt.Errorf("error updating environment (%s) sequencing: %v", environmentID, err)
+	}
+
+	return waitForEnvironmentReady(ctx, d, meta, environmentID, schemaTimeout)
+}
+
 func resourceSkytapEnvironmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*SkytapClient).environmentsClient
 
@@ -478,6 +969,47 @@ var environmentTargetUpdateRunstates = []string{
 	string(skytap.EnvironmentRunstateSuspended),
 }
 
+var environmentRunstates = []string{
+	string(skytap.EnvironmentRunstateBusy),
+	string(skytap.EnvironmentRunstateRunning),
+	string(skytap.EnvironmentRunstateStopped),
+	string(skytap.EnvironmentRunstateSuspended),
+	string(skytap.EnvironmentRunstateHalted),
+}
+
+// environmentTargetRunstates returns the runstates reached once the requested runstate is applied.
+// Halting shuts the VMs down through the guest OS, leaving the environment stopped.
+func environmentTargetRunstates(runstate skytap.EnvironmentRunstate) []string {
+	if runstate == skytap.EnvironmentRunstateHalted {
+		return []string{string(skytap.EnvironmentRunstateHalted), string(skytap.EnvironmentRunstateStopped)}
+	}
+	return []string{string(runstate)}
+}
+
+// environmentPendingRunstates returns every runstate which is not a target, as the environment might not
+// have left its previous runstate yet
+func environmentPendingRunstates(target []string) []string {
+	pending := make([]string, 0)
+	for _, runstate := range environmentRunstates {
+		isTarget := false
+		for _, t := range target {
+			if t == runstate {
+				isTarget = true
+				break
+			}
+		}
+		if !isTarget {
+			pending = append(pending, runstate)
+		}
+	}
+	return pending
+}
+
+// environmentRunstateSuppress ignores a `stopped` environment when it was requested to be `halted`
+func environmentRunstateSuppress(_, old, new string, _ *schema.ResourceData) bool {
+	return new == string(skytap.EnvironmentRunstateHalted) && old == string(skytap.EnvironmentRunstateStopped)
+}
+
 func environmentCreateRunstateRefreshFunc(
 	ctx context.Context, d *schema.ResourceData, meta interface{}) resource.StateRefreshFunc {
 	return func() (interface{}, string, error) {
@@ -492,6 +1024,10 @@ func environmentCreateRunstateRefreshFunc(
 			return nil, "", fmt.Errorf("error retrieving environment (%s) when waiting: %v", id, err)
 		}
 
+		if err = environmentError(environment); err != nil {
+			return nil, "", err
+		}
+
 		computedRunstate := skytap.EnvironmentRunstateRunning
 		for i := 0; i < *environment.VMCount; i++ {
 			if *environment.VMs[i].Runstate != skytap.VMRunstateRunning {
@@ -506,7 +1042,11 @@ func environmentCreateRunstateRefreshFunc(
 	}
 }
 
-func environmentUpdateRunstateRefreshFunc(ctx context.Context, meta interface{}, environmentID string) resource.StateRefreshFunc {
+// environmentUpdateRunstateRefreshFunc refreshes the runstate of the environment. The errors reported on the environment
+// only stop the wait when checkErrors is set, for the environment's own runstate changes. Waits shared with the
+// resources within the environment ignore them, as an error left on the environment is not caused by their changes.
+func environmentUpdateRunstateRefreshFunc(ctx context.Context, meta interface{}, environmentID string,
+	checkErrors bool) resource.StateRefreshFunc {
 	return func() (interface{}, string, error) {
 		client := meta.(*SkytapClient).environmentsClient
 
@@ -517,12 +1057,56 @@ func environmentUpdateRunstateRefreshFunc(ctx context.Context, meta interface{},
 			return nil, "", fmt.Errorf("error retrieving environment (%s) when waiting: %v", environmentID, err)
 		}
 
+		if checkErrors {
+			if err = environmentError(environment); err != nil {
+				return nil, "", err
+			}
+		}
+
 		log.Printf("[DEBUG] environment (%s): %s", environmentID, *environment.Runstate)
 
 		return environment, string(*environment.Runstate), nil
 	}
 }
 
+// environmentError returns the errors reported on the environment, if any, so that waiting stops
+// straight away instead of running until the timeout
+func environmentError(environment *skytap.Environment) error {
+	messages := make([]string, 0)
+	messages = append(messages, environment.Errors...)
+	messages = append(messages, environment.ErrorDetails...)
+	messages = append(messages, environment.PlatformErrors...)
+	if len(messages) == 0 {
+		return nil
+	}
+
+	id := ""
+	if environment.ID != nil {
+		id = *environment.ID
+	}
+	return fmt.Errorf("environment (%s) reported errors: %s", id, strings.Join(messages, "; "))
+}
+
+// environmentAlertDiagnostics returns a warning for each alert on the environment which cannot be dismissed
+func environmentAlertDiagnostics(environment *skytap.Environment) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, alert := range environment.Alerts {
+		if alert.Dismissable {
+			continue
+		}
+		id := ""
+		if environment.ID != nil {
+			id = *environment.ID
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("environment (%s) alert", id),
+			Detail:   alert.Message,
+		})
+	}
+	return diags
+}
+
 func environmentDeleteRefreshFunc(
 	ctx context.Context, d *schema.ResourceData, meta interface{}) resource.StateRefreshFunc {
 	return func() (interface{}, string, error) {
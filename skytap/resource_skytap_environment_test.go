@@ -8,10 +8,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
 )
@@ -49,6 +51,32 @@ func testSweepSkytapEnvironment(region string) error {
 	return nil
 }
 
+func TestEnvironmentError(t *testing.T) {
+	environment := skytap.Environment{ID: utils.String("123")}
+	assert.NoError(t, environmentError(&environment))
+
+	environment.Errors = []string{"VM could not be started"}
+	environment.PlatformErrors = []string{"Insufficient capacity in region"}
+	err := environmentError(&environment)
+	assert.EqualError(t, err, "environment (123) reported errors: VM could not be started; Insufficient capacity in region")
+}
+
+func TestEnvironmentAlertDiagnostics(t *testing.T) {
+	environment := skytap.Environment{
+		ID: utils.String("123"),
+		Alerts: []skytap.Alert{
+			{ID: "1", Dismissable: true, Message: "Dismissable alert"},
+			{ID: "2", Dismissable: false, Message: "Scheduled maintenance"},
+		},
+	}
+
+	diags := environmentAlertDiagnostics(&environment)
+	assert.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Scheduled maintenance", diags[0].Detail)
+	assert.False(t, diags.HasError())
+}
+
 func TestAccSkytapEnvironment_Basic(t *testing.T) {
 	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
 	uniqueSuffix := acctest.RandInt()
@@ -73,8 +101,19 @@ func TestAccSkytapEnvironment_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("skytap_environment.foo", "shutdown_on_idle", "0"),
 					resource.TestCheckResourceAttr("skytap_environment.foo", "shutdown_at_time", ""),
 					resource.TestCheckResourceAttr("skytap_environment.foo", "tags.#", "1"),
+					resource.TestCheckResourceAttrSet("skytap_environment.foo", "region"),
+					resource.TestCheckResourceAttrSet("skytap_environment.foo", "owner_name"),
+					resource.TestCheckResourceAttrSet("skytap_environment.foo", "created_at"),
+					resource.TestCheckResourceAttrPair("skytap_environment.foo", "vms.#", "skytap_environment.foo", "vm_count"),
+					resource.TestCheckResourceAttrPair("skytap_environment.foo", "networks.#", "skytap_environment.foo", "network_count"),
 				),
 			},
+			{
+				ResourceName:            "skytap_environment.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"template_id"},
+			},
 		},
 	})
 }
@@ -209,6 +248,111 @@ func TestAccSkytapEnvironment_UserDataUpdate(t *testing.T) {
 	})
 }
 
+func TestAccSkytapEnvironment_Runstate(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+	uniqueSuffix := acctest.RandInt()
+	var environment skytap.Environment
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapEnvironmentConfigBlock(uniqueSuffix, templateID, "", `runstate = "suspended"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapEnvironmentExists("skytap_environment.foo", &environment),
+					resource.TestCheckResourceAttr("skytap_environment.foo", "runstate", "suspended"),
+				),
+			},
+			{
+				Config: testAccSkytapEnvironmentConfigBlock(uniqueSuffix, templateID, "", `runstate = "running"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapEnvironmentExists("skytap_environment.foo", &environment),
+					resource.TestCheckResourceAttr("skytap_environment.foo", "runstate", "running"),
+				),
+			},
+			{
+				Config: testAccSkytapEnvironmentConfigBlock(uniqueSuffix, templateID, "", `runstate = "stopped"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapEnvironmentExists("skytap_environment.foo", &environment),
+					resource.TestCheckResourceAttr("skytap_environment.foo", "runstate", "stopped"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkytapEnvironment_Sequencing(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+	uniqueSuffix := acctest.RandInt()
+	var environment skytap.Environment
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapEnvironmentConfigBlock(uniqueSuffix, templateID, "", `sequencing_enabled = true`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapEnvironmentExists("skytap_environment.foo", &environment),
+					resource.TestCheckResourceAttr("skytap_environment.foo", "sequencing_enabled", "true"),
+					resource.TestCheckResourceAttrSet("skytap_environment.foo", "stage.#"),
+				),
+			},
+			{
+				Config: testAccSkytapEnvironmentConfigBlock(uniqueSuffix, templateID, "", `sequencing_enabled = false`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapEnvironmentExists("skytap_environment.foo", &environment),
+					resource.TestCheckResourceAttr("skytap_environment.foo", "sequencing_enabled", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkytapEnvironment_Project(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+	uniqueSuffix := acctest.RandInt()
+	var environment skytap.Environment
+
+	projectRequirements := fmt.Sprintf(`
+		resource "skytap_project" "one" {
+			name = "tftest-project-one-%d"
+		}
+		resource "skytap_project" "two" {
+			name = "tftest-project-two-%d"
+		}`, uniqueSuffix, uniqueSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapEnvironmentConfigBlock(uniqueSuffix, templateID, projectRequirements,
+					"project_id = skytap_project.one.id"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapEnvironmentExists("skytap_environment.foo", &environment),
+					resource.TestCheckResourceAttrPair("skytap_environment.foo", "project_id", "skytap_project.one", "id"),
+					resource.TestCheckResourceAttr("skytap_environment.foo", "project_ids.#", "1"),
+					resource.TestCheckResourceAttrSet("skytap_environment.foo", "owner"),
+				),
+			},
+			{
+				Config: testAccSkytapEnvironmentConfigBlock(uniqueSuffix, templateID, projectRequirements,
+					"project_id = skytap_project.two.id"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapEnvironmentExists("skytap_environment.foo", &environment),
+					resource.TestCheckResourceAttrPair("skytap_environment.foo", "project_id", "skytap_project.two", "id"),
+					resource.TestCheckResourceAttr("skytap_environment.foo", "project_ids.#", "1"),
+				),
+			},
+		},
+	})
+}
+
 const labelRequirements = `
 		resource skytap_label_category "environment_label" {
 			name = "tftest-Environment"
@@ -464,6 +608,16 @@ func getEnvironment(rs *terraform.ResourceState) (*skytap.Environment, error) {
 	return environment, err
 }
 
+func testAccSkytapEnvironmentChildImportStateIDFunc(name string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, err := getResource(s, name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s/%s", rs.Primary.Attributes["environment_id"], rs.Primary.ID), nil
+	}
+}
+
 func getResource(s *terraform.State, name string) (*terraform.ResourceState, error) {
 	rs, ok := s.RootModule().Resources[name]
 	var err error
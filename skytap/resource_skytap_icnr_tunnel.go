@@ -2,11 +2,14 @@ package skytap
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/skytap/skytap-sdk-go/skytap"
 
 	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
 )
@@ -16,6 +19,9 @@ func resourceSkytapICNRTunnel() *schema.Resource {
 		CreateContext: resourceSkytapICNRTunnelCreate,
 		ReadContext:   resourceSkytapICNRTunnelRead,
 		DeleteContext: resourceSkytapICNRTunnelDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
@@ -60,7 +66,7 @@ func resourceSkytapICNRTunnelRead(ctx context.Context, d *schema.ResourceData, m
 	client := meta.(*SkytapClient).icnrTunnelClient
 
 	id := d.Id()
-	_, err := client.Get(ctx, id)
+	tunnel, err := client.Get(ctx, id)
 	if err != nil {
 		if utils.ResponseErrorIsNotFound(err) {
 			log.Printf("[DEBUG] ICNR tunnel (%s) was not found - removing from state", id)
@@ -70,6 +76,24 @@ func resourceSkytapICNRTunnelRead(ctx context.Context, d *schema.ResourceData, m
 
 		return diag.FromErr(err)
 	}
+
+	source, err := flattenTunnelNetworkID(tunnel.Source)
+	if err != nil {
+		return diag.Errorf("error reading ICNR tunnel (%s) source: %v", id, err)
+	}
+	err = d.Set("source", source)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	target, err := flattenTunnelNetworkID(tunnel.Target)
+	if err != nil {
+		return diag.Errorf("error reading ICNR tunnel (%s) target: %v", id, err)
+	}
+	err = d.Set("target", target)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	return nil
 }
 
@@ -90,3 +114,11 @@ func resourceSkytapICNRTunnelDelete(ctx context.Context, d *schema.ResourceData,
 
 	return nil
 }
+
+// flattenTunnelNetworkID returns the numeric ID of the network at one end of the tunnel
+func flattenTunnelNetworkID(network *skytap.Network) (int, error) {
+	if network == nil || network.ID == nil {
+		return 0, fmt.Errorf("network is not set")
+	}
+	return strconv.Atoi(*network.ID)
+}
@@ -30,6 +30,11 @@ func TestAccSkytapLabelICNR_Basic(t *testing.T) {
 					testAccCheckSkytapICNRTunnelExists("skytap_icnr_tunnel.tunnel", &tunnel),
 				),
 			},
+			{
+				ResourceName:      "skytap_icnr_tunnel.tunnel",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
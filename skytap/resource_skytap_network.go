@@ -2,6 +2,7 @@ package skytap
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"regexp"
 	"time"
@@ -21,6 +22,11 @@ func resourceSkytapNetwork() *schema.Resource {
 		ReadContext:   resourceSkytapNetworkRead,
 		UpdateContext: resourceSkytapNetworkUpdate,
 		DeleteContext: resourceSkytapNetworkDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateNetwork,
+		},
+
+		CustomizeDiff: resourceSkytapNetworkTypeDiff,
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
@@ -44,10 +50,19 @@ func resourceSkytapNetwork() *schema.Resource {
 				ValidateFunc: validation.StringLenBetween(1, 255),
 			},
 
+			"network_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      string(skytap.NetworkTypeAutomatic),
+				Description:  "The type of the network: `automatic`, where Skytap provides DHCP, DNS and a gateway, or `manual`, an isolated network whose addressing is managed by the VMs",
+				ValidateFunc: validateNetworkType(),
+			},
+
 			"domain": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Domain name for the Skytap network. This field can be changed only when all virtual machines in the environment are stopped (not suspended or running)",
+				Optional:    true,
+				Computed:    true,
+				Description: "Domain name for the Skytap network. Required for `automatic` networks, and cannot be set for `manual` networks. This field can be changed only when all virtual machines in the environment are stopped (not suspended or running)",
 				ValidateFunc: validation.All(
 					validation.NoZeroValues,
 					validation.StringLenBetween(1, 64),
@@ -70,12 +85,42 @@ func resourceSkytapNetwork() *schema.Resource {
 				ValidateFunc: validation.IsIPAddress,
 			},
 
+			"primary_nameserver": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The IP address of the primary DNS server of the network",
+				ValidateFunc: validation.IsIPv4Address,
+			},
+
+			"secondary_nameserver": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The IP address of the secondary DNS server of the network",
+				ValidateFunc: validation.IsIPv4Address,
+			},
+
 			"tunnelable": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "Whether or not this network can be connected to other networks",
 			},
+
+			"adopt_existing": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Description:      "Adopt the network of the environment with the same `name` or `subnet`, such as a network created from the template, instead of creating a new network. The settings of the network are then updated to match the configuration. Changing it adopts the network again, or creates one",
+				ValidateFunc:     validation.StringInSlice([]string{"name", "subnet"}, false),
+				DiffSuppressFunc: importedSuppress,
+			},
+
+			"keep_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the network is left in the environment, instead of deleted, when the resource is destroyed. Defaults to `true` for an adopted network, and `false` otherwise",
+			},
 		},
 	}
 }
@@ -84,21 +129,35 @@ func resourceSkytapNetworkCreate(ctx context.Context, d *schema.ResourceData, me
 	client := meta.(*SkytapClient).networksClient
 
 	environmentID := d.Get("environment_id").(string)
+
+	if _, ok := d.GetOkExists("keep_on_destroy"); !ok {
+		if err := d.Set("keep_on_destroy", d.Get("adopt_existing").(string) != ""); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if matchBy, ok := d.GetOk("adopt_existing"); ok {
+		return adoptNetwork(ctx, d, meta, matchBy.(string))
+	}
+
 	name := d.Get("name").(string)
-	domain := d.Get("domain").(string)
+	networkType := skytap.NetworkType(d.Get("network_type").(string))
 	subnet := d.Get("subnet").(string)
 	tunnelable := d.Get("tunnelable").(bool)
 
 	opts := skytap.CreateNetworkRequest{
 		Name:        &name,
-		NetworkType: utils.NetworkType(skytap.NetworkTypeAutomatic),
-		Domain:      &domain,
+		NetworkType: &networkType,
 		Subnet:      &subnet,
 		Tunnelable:  &tunnelable,
 	}
 
-	if v, ok := d.GetOk("gateway"); ok {
-		opts.Gateway = utils.String(v.(string))
+	// manual networks have no domain or gateway
+	if networkType == skytap.NetworkTypeAutomatic {
+		opts.Domain = utils.String(d.Get("domain").(string))
+		if v, ok := d.GetOk("gateway"); ok {
+			opts.Gateway = utils.String(v.(string))
+		}
 	}
 
 	log.Printf("[INFO] network create")
@@ -121,6 +180,14 @@ func resourceSkytapNetworkCreate(ctx context.Context, d *schema.ResourceData, me
 		return diag.FromErr(err)
 	}
 
+	configured := func(key string) bool {
+		_, ok := d.GetOk(key)
+		return ok && key != "network_type"
+	}
+	if err = updateNetworkSettings(ctx, d, meta, configured, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return resourceSkytapNetworkRead(ctx, d, meta)
 }
 
@@ -150,6 +217,12 @@ func resourceSkytapNetworkRead(ctx context.Context, d *schema.ResourceData, meta
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	if network.NetworkType != nil {
+		err = d.Set("network_type", string(*network.NetworkType))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	err = d.Set("domain", network.Domain)
 	if err != nil {
 		return diag.FromErr(err)
@@ -166,6 +239,14 @@ func resourceSkytapNetworkRead(ctx context.Context, d *schema.ResourceData, meta
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = d.Set("primary_nameserver", network.PrimaryNameserver)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("secondary_nameserver", network.SecondaryNameserver)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	log.Printf("[INFO] network retrieved: %s", id)
 	log.Printf("[TRACE] network retrieved: %v", spew.Sdump(network))
@@ -174,42 +255,57 @@ func resourceSkytapNetworkRead(ctx context.Context, d *schema.ResourceData, meta
 }
 
 func resourceSkytapNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := updateNetwork(ctx, d, meta, d.HasChange, schema.TimeoutUpdate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSkytapNetworkRead(ctx, d, meta)
+}
+
+// updateNetwork applies the arguments selected by include to the network
+func updateNetwork(ctx context.Context, d *schema.ResourceData, meta interface{}, include func(string) bool,
+	schemaTimeout string) error {
 	client := meta.(*SkytapClient).networksClient
 
 	id := d.Id()
 
 	environmentID := d.Get("environment_id").(string)
 	name := d.Get("name").(string)
-	domain := d.Get("domain").(string)
 	subnet := d.Get("subnet").(string)
 	tunnelable := d.Get("tunnelable").(bool)
 
+	// the network type is changed first, so the domain and gateway of a network becoming automatic are accepted
+	if err := updateNetworkSettings(ctx, d, meta, include, schemaTimeout); err != nil {
+		return err
+	}
+	if !include("name") && !include("domain") && !include("subnet") && !include("gateway") && !include("tunnelable") {
+		return nil
+	}
+
 	opts := skytap.UpdateNetworkRequest{
 		Name:       &name,
-		Domain:     &domain,
 		Subnet:     &subnet,
 		Tunnelable: &tunnelable,
 	}
 
-	if v, ok := d.GetOk("gateway"); ok {
-		opts.Gateway = utils.String(v.(string))
+	if d.Get("network_type").(string) == string(skytap.NetworkTypeAutomatic) {
+		opts.Domain = utils.String(d.Get("domain").(string))
+		if v, ok := d.GetOk("gateway"); ok {
+			opts.Gateway = utils.String(v.(string))
+		}
 	}
 
 	log.Printf("[INFO] network update: %s", id)
 	log.Printf("[TRACE] network update options: %v", spew.Sdump(opts))
 	network, err := client.Update(ctx, environmentID, id, &opts)
 	if err != nil {
-		return diag.Errorf("error updating network (%s): %v", id, err)
+		return fmt.Errorf("error updating network (%s): %v", id, err)
 	}
 
 	log.Printf("[INFO] network updated: %s", id)
 	log.Printf("[TRACE] network updated: %v", spew.Sdump(network))
 
-	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutUpdate); err != nil {
-		return diag.FromErr(err)
-	}
-
-	return resourceSkytapNetworkRead(ctx, d, meta)
+	return waitForEnvironmentReady(ctx, d, meta, environmentID, schemaTimeout)
 }
 
 func resourceSkytapNetworkDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -218,6 +314,11 @@ func resourceSkytapNetworkDelete(ctx context.Context, d *schema.ResourceData, me
 	environmentID := d.Get("environment_id").(string)
 	id := d.Id()
 
+	if d.Get("keep_on_destroy").(bool) {
+		log.Printf("[INFO] keeping network (%s) in environment (%s) - removing from state", id, environmentID)
+		return nil
+	}
+
 	log.Printf("[INFO] destroying network: %s", id)
 	err := client.Delete(ctx, environmentID, id)
 	if err != nil {
@@ -236,3 +337,140 @@ func resourceSkytapNetworkDelete(ctx context.Context, d *schema.ResourceData, me
 
 	return nil
 }
+
+// importStateNetwork imports a network using an ID of the form `environment_id/id`. The arguments which are only kept
+// in the state take their defaults.
+func importStateNetwork(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := d.Set("keep_on_destroy", false); err != nil {
+		return nil, err
+	}
+	return importStateEnvironmentChild(ctx, d, meta)
+}
+
+// adoptNetwork takes ownership of the network of the environment matching the configured name or subnet, then
+// updates it to match the configuration
+func adoptNetwork(ctx context.Context, d *schema.ResourceData, meta interface{}, matchBy string) diag.Diagnostics {
+	client := meta.(*SkytapClient).networksClient
+
+	environmentID := d.Get("environment_id").(string)
+
+	if err := waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] finding network to adopt in environment: %s", environmentID)
+	networks, err := client.List(ctx, environmentID)
+	if err != nil {
+		return diag.Errorf("error retrieving networks of environment (%s): %v", environmentID, err)
+	}
+	network, err := findAdoptableNetwork(networks.Value, matchBy, d.Get(matchBy).(string))
+	if err != nil {
+		return diag.Errorf("error adopting network in environment (%s): %v", environmentID, err)
+	}
+	d.SetId(*network.ID)
+
+	log.Printf("[INFO] network adopted: %s", *network.ID)
+	log.Printf("[TRACE] network adopted: %v", spew.Sdump(network))
+
+	// every configured argument is a change from the empty state
+	if err = updateNetwork(ctx, d, meta, d.HasChange, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSkytapNetworkRead(ctx, d, meta)
+}
+
+// findAdoptableNetwork returns the only network whose name or subnet, as given by matchBy, is the value
+func findAdoptableNetwork(networks []skytap.Network, matchBy string, value string) (*skytap.Network, error) {
+	var found *skytap.Network
+	for idx := range networks {
+		network := &networks[idx]
+		var actual *string
+		if matchBy == "subnet" {
+			actual = network.Subnet
+		} else {
+			actual = network.Name
+		}
+		if actual == nil || *actual != value || network.ID == nil {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("more than one network with the %s (%s) was found", matchBy, value)
+		}
+		found = network
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no network with the %s (%s) was found", matchBy, value)
+	}
+	return found, nil
+}
+
+// resourceSkytapNetworkTypeDiff requires a domain for automatic networks. Manual networks have no domain or gateway,
+// so neither is required, and a domain set on a manual network is rejected as it would never be applied.
+func resourceSkytapNetworkTypeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.NewValueKnown("network_type") {
+		return nil
+	}
+	if d.Get("network_type").(string) == string(skytap.NetworkTypeManual) {
+		if d.HasChange("domain") && d.Get("domain").(string) != "" {
+			return fmt.Errorf("domain cannot be set for %s networks", skytap.NetworkTypeManual)
+		}
+		return nil
+	}
+	if d.NewValueKnown("domain") && d.Get("domain").(string) == "" {
+		return fmt.Errorf("domain is required for %s networks", skytap.NetworkTypeAutomatic)
+	}
+	return nil
+}
+
+// networkSettingsRequest updates the network settings which are not supported by the SDK
+type networkSettingsRequest struct {
+	NetworkType         *string `json:"network_type,omitempty"`
+	PrimaryNameserver   *string `json:"primary_nameserver,omitempty"`
+	SecondaryNameserver *string `json:"secondary_nameserver,omitempty"`
+}
+
+// expandNetworkSettings builds the request for the settings selected by include, or returns nil if there are none.
+// A nameserver which is no longer set is cleared.
+func expandNetworkSettings(d *schema.ResourceData, include func(string) bool) *networkSettingsRequest {
+	opts := &networkSettingsRequest{}
+	updated := false
+
+	if include("network_type") {
+		opts.NetworkType = utils.String(d.Get("network_type").(string))
+		updated = true
+	}
+	if include("primary_nameserver") {
+		opts.PrimaryNameserver = utils.String(d.Get("primary_nameserver").(string))
+		updated = true
+	}
+	if include("secondary_nameserver") {
+		opts.SecondaryNameserver = utils.String(d.Get("secondary_nameserver").(string))
+		updated = true
+	}
+
+	if !updated {
+		return nil
+	}
+	return opts
+}
+
+// updateNetworkSettings applies the settings selected by include
+func updateNetworkSettings(ctx context.Context, d *schema.ResourceData, meta interface{}, include func(string) bool,
+	schemaTimeout string) error {
+	opts := expandNetworkSettings(d, include)
+	if opts == nil {
+		return nil
+	}
+
+	environmentID := d.Get("environment_id").(string)
+	id := d.Id()
+
+	log.Printf("[INFO] network (%s) updating settings", id)
+	log.Printf("[TRACE] network updating settings: %v", spew.Sdump(opts))
+	path := fmt.Sprintf("v2/configurations/%s/networks/%s.json", environmentID, id)
+	if err := meta.(*SkytapClient).apiClient.do(ctx, "PUT", path, opts, nil); err != nil {
+		return fmt.Errorf("error updating network (%s) settings: %v", id, err)
+	}
+	return waitForEnvironmentReady(ctx, d, meta, environmentID, schemaTimeout)
+}
@@ -0,0 +1,498 @@
+package skytap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/skytap/skytap-sdk-go/skytap"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func resourceSkytapNetworkInterface() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSkytapNetworkInterfaceCreate,
+		ReadContext:   resourceSkytapNetworkInterfaceRead,
+		UpdateContext: resourceSkytapNetworkInterfaceUpdate,
+		DeleteContext: resourceSkytapNetworkInterfaceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateVMChild,
+		},
+
+		CustomizeDiff: resourceSkytapNetworkInterfaceSecondaryIPsDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"environment_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the environment containing the VM",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"vm_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the VM you want to add the network interface to",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"nic_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				Description:  "Type of the network adapter. If not set, Skytap chooses the type which suits the guest OS of the VM",
+				ValidateFunc: validateNICType(),
+			},
+
+			"network_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "ID of the network that this network adapter is attached to. Changing it moves the adapter to the new network",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"ip": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The IP address (for example, 10.1.0.37). Skytap will not assign the same IP address to multiple interfaces on the same network",
+				ValidateFunc: validation.IsIPAddress,
+			},
+
+			"hostname": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "Hostname of the VM on the network",
+				ValidateFunc: validateHostname(),
+			},
+
+			"secondary_ips": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Set of secondary IP addresses of the network adapter, such as floating addresses shared by a cluster. Each address must be within the subnet of the attached network",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsIPv4Address,
+				},
+			},
+
+			// computed attributes
+			"mac": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The MAC address of the network adapter",
+			},
+
+			"network_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the network the adapter is attached to",
+			},
+
+			"network_subnet": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The subnet of the network the adapter is attached to",
+			},
+
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The status of the network adapter",
+			},
+		},
+	}
+}
+
+func resourceSkytapNetworkInterfaceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).interfacesClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+
+	nicType := skytap.CreateInterfaceRequest{
+		NICType: utils.NICType(skytap.NICTypeDefault),
+	}
+	if v, ok := d.GetOk("nic_type"); ok {
+		nicType.NICType = utils.NICType(skytap.NICType(v.(string)))
+	}
+	networkID := skytap.AttachInterfaceRequest{
+		NetworkID: utils.String(d.Get("network_id").(string)),
+	}
+	opts, requiresUpdate := networkInterfaceUpdateRequest(d)
+
+	if err := waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	err := updateStoppedVM(ctx, meta, environmentID, vmID, d.Timeout(schema.TimeoutCreate), func() error {
+		log.Printf("[INFO] creating interface")
+		log.Printf("[TRACE] creating interface: %v", spew.Sdump(nicType))
+		networkInterface, err := client.Create(ctx, environmentID, vmID, &nicType)
+		if err != nil {
+			return fmt.Errorf("error creating interface: %v", err)
+		}
+		if networkInterface.ID == nil {
+			return fmt.Errorf("interface ID is not set")
+		}
+		id := *networkInterface.ID
+		d.SetId(id)
+
+		log.Printf("[INFO] created interface: %s", id)
+		log.Printf("[TRACE] created interface: %v", spew.Sdump(networkInterface))
+
+		if err = attachNetworkInterface(ctx, meta, environmentID, vmID, id, &networkID); err != nil {
+			return err
+		}
+		if requiresUpdate {
+			if err = updateNetworkInterface(ctx, meta, environmentID, vmID, id, opts); err != nil {
+				return err
+			}
+		}
+		if v, ok := d.GetOk("secondary_ips"); ok {
+			err = updateNetworkInterfaceSecondaryIPs(ctx, meta, environmentID, vmID, id, v.(*schema.Set), nil)
+			if err != nil {
+				return err
+			}
+		}
+		return waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate)
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSkytapNetworkInterfaceRead(ctx, d, meta)
+}
+
+func resourceSkytapNetworkInterfaceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).interfacesClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	id := d.Id()
+
+	log.Printf("[INFO] retrieving interface: %s", id)
+	networkInterface, err := client.Get(ctx, environmentID, vmID, id)
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] interface (%s) was not found - removing from state", id)
+			d.SetId("")
+			return nil
+		}
+
+		return diag.Errorf("error retrieving interface (%s): %v", id, err)
+	}
+
+	err = d.Set("environment_id", environmentID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("vm_id", vmID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if networkInterface.NICType != nil {
+		err = d.Set("nic_type", string(*networkInterface.NICType))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	err = d.Set("network_id", networkInterface.NetworkID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("ip", networkInterface.IP)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("hostname", networkInterface.Hostname)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("mac", networkInterface.MAC)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("network_name", networkInterface.NetworkName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("network_subnet", networkInterface.NetworkSubnet)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("status", networkInterface.Status)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("secondary_ips", flattenSecondaryIPs(networkInterface.SecondaryIPs))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] interface retrieved: %s", id)
+	log.Printf("[TRACE] interface retrieved: %v", spew.Sdump(networkInterface))
+
+	return nil
+}
+
+func resourceSkytapNetworkInterfaceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	id := d.Id()
+
+	opts, requiresUpdate := networkInterfaceUpdateRequest(d)
+	if !d.HasChanges("network_id", "secondary_ips") && !requiresUpdate {
+		return resourceSkytapNetworkInterfaceRead(ctx, d, meta)
+	}
+
+	secondaryIPsOld, secondaryIPsNew := d.GetChange("secondary_ips")
+	addSecondaryIPs := secondaryIPsNew.(*schema.Set).Difference(secondaryIPsOld.(*schema.Set))
+	removeSecondaryIPs := secondaryIPsOld.(*schema.Set).Difference(secondaryIPsNew.(*schema.Set))
+
+	err := updateStoppedVM(ctx, meta, environmentID, vmID, d.Timeout(schema.TimeoutUpdate), func() error {
+		if d.HasChange("network_id") {
+			// the secondary IPs are within the subnet of the old network, so they are all removed before the
+			// interface moves, and the whole set is added back once it is attached to the new network
+			err := updateNetworkInterfaceSecondaryIPs(ctx, meta, environmentID, vmID, id, nil, secondaryIPsOld.(*schema.Set))
+			if err != nil {
+				return err
+			}
+			addSecondaryIPs, removeSecondaryIPs = secondaryIPsNew.(*schema.Set), nil
+
+			networkID := skytap.AttachInterfaceRequest{
+				NetworkID: utils.String(d.Get("network_id").(string)),
+			}
+			if err = attachNetworkInterface(ctx, meta, environmentID, vmID, id, &networkID); err != nil {
+				return err
+			}
+		}
+		if requiresUpdate {
+			if err := updateNetworkInterface(ctx, meta, environmentID, vmID, id, opts); err != nil {
+				return err
+			}
+		}
+		if d.HasChange("network_id") || d.HasChange("secondary_ips") {
+			err := updateNetworkInterfaceSecondaryIPs(ctx, meta, environmentID, vmID, id, addSecondaryIPs, removeSecondaryIPs)
+			if err != nil {
+				return err
+			}
+		}
+		return waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutUpdate)
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSkytapNetworkInterfaceRead(ctx, d, meta)
+}
+
+func resourceSkytapNetworkInterfaceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).interfacesClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	id := d.Id()
+
+	err := updateStoppedVM(ctx, meta, environmentID, vmID, d.Timeout(schema.TimeoutDelete), func() error {
+		log.Printf("[INFO] destroying interface: %s", id)
+		if err := client.Delete(ctx, environmentID, vmID, id); err != nil {
+			if utils.ResponseErrorIsNotFound(err) {
+				log.Printf("[DEBUG] interface (%s) was not found - assuming removed", id)
+				return nil
+			}
+			return fmt.Errorf("error deleting interface (%s): %v", id, err)
+		}
+		return waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutDelete)
+	})
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] VM (%s) was not found - assuming interface (%s) removed", vmID, id)
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] interface destroyed: %s", id)
+
+	return nil
+}
+
+// networkInterfaceUpdateRequest builds the request for the IP address and hostname, which are only sent when
+// they have changed. The IP address is sent again when the interface moves to another network, so that it is kept.
+// It reports whether an update is required.
+func networkInterfaceUpdateRequest(d *schema.ResourceData) (*skytap.UpdateInterfaceRequest, bool) {
+	opts := &skytap.UpdateInterfaceRequest{}
+	requiresUpdate := false
+	if v, ok := d.GetOk("ip"); ok && (d.HasChange("ip") || d.HasChange("network_id")) {
+		opts.IP = utils.String(v.(string))
+		requiresUpdate = true
+	}
+	if v, ok := d.GetOk("hostname"); ok && d.HasChange("hostname") {
+		opts.Hostname = utils.String(v.(string))
+		requiresUpdate = true
+	}
+	return opts, requiresUpdate
+}
+
+func attachNetworkInterface(ctx context.Context, meta interface{}, environmentID string, vmID string, id string,
+	networkID *skytap.AttachInterfaceRequest) error {
+	client := meta.(*SkytapClient).interfacesClient
+
+	log.Printf("[INFO] attaching interface: %s", id)
+	log.Printf("[TRACE] attaching interface: %v", spew.Sdump(networkID))
+	networkInterface, err := client.Attach(ctx, environmentID, vmID, id, networkID)
+	if err != nil {
+		return fmt.Errorf("error attaching interface (%s): %v", id, err)
+	}
+
+	log.Printf("[INFO] attached interface: %s", id)
+	log.Printf("[TRACE] attached interface: %v", spew.Sdump(networkInterface))
+	return nil
+}
+
+func updateNetworkInterface(ctx context.Context, meta interface{}, environmentID string, vmID string, id string,
+	opts *skytap.UpdateInterfaceRequest) error {
+	client := meta.(*SkytapClient).interfacesClient
+
+	log.Printf("[INFO] updating interface: %s", id)
+	log.Printf("[TRACE] updating interface options: %v", spew.Sdump(opts))
+	networkInterface, err := client.Update(ctx, environmentID, vmID, id, opts)
+	if err != nil {
+		return fmt.Errorf("error updating interface (%s): %v", id, err)
+	}
+
+	log.Printf("[INFO] updated interface: %s", id)
+	log.Printf("[TRACE] updated interface: %v", spew.Sdump(networkInterface))
+	return nil
+}
+
+// resourceSkytapNetworkInterfaceSecondaryIPsDiff checks that the IP address and the secondary IP addresses are within
+// the subnet of the network when planning, including when the interface moves to another network. An IP address
+// which is not changed along with the network is kept only if it is within the new subnet, otherwise Skytap assigns
+// a new one. Networks which are not created yet are checked when the addresses are added.
+func resourceSkytapNetworkInterfaceSecondaryIPsDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	networkChanged := d.Id() != "" && d.HasChange("network_id")
+	ip := d.Get("ip").(string)
+	checkIP := ip != "" && d.NewValueKnown("ip") && (d.HasChange("ip") || networkChanged)
+	secondaryIPs := d.Get("secondary_ips").(*schema.Set)
+	checkSecondaryIPs := secondaryIPs.Len() > 0 && d.NewValueKnown("secondary_ips") &&
+		(d.HasChange("secondary_ips") || d.HasChange("network_id"))
+	if !checkIP && !checkSecondaryIPs {
+		return nil
+	}
+	if !d.NewValueKnown("environment_id") || !d.NewValueKnown("network_id") {
+		if checkIP && !d.HasChange("ip") {
+			return d.SetNewComputed("ip")
+		}
+		return nil
+	}
+
+	client := meta.(*SkytapClient).networksClient
+	networkID := d.Get("network_id").(string)
+	network, err := client.Get(ctx, d.Get("environment_id").(string), networkID)
+	if err != nil {
+		return fmt.Errorf("error retrieving network (%s): %v", networkID, err)
+	}
+	if network.Subnet == nil {
+		return nil
+	}
+	if checkIP {
+		if err = checkIPsInSubnet([]string{ip}, *network.Subnet); err != nil {
+			if d.HasChange("ip") {
+				return err
+			}
+			log.Printf("[DEBUG] IP (%s) is not within the subnet of network (%s) - a new one is assigned", ip, networkID)
+			if err = d.SetNewComputed("ip"); err != nil {
+				return err
+			}
+		}
+	}
+	if checkSecondaryIPs {
+		return checkIPsInSubnet(expandStringSet(secondaryIPs), *network.Subnet)
+	}
+	return nil
+}
+
+// updateNetworkInterfaceSecondaryIPs removes and adds the secondary IP addresses of the network interface. The
+// addresses to add are checked against the subnet of the network the interface is attached to.
+func updateNetworkInterfaceSecondaryIPs(ctx context.Context, meta interface{}, environmentID string, vmID string, id string,
+	add *schema.Set, remove *schema.Set) error {
+	client := meta.(*SkytapClient).interfacesClient
+	apiClient := meta.(*SkytapClient).apiClient
+
+	networkInterface, err := client.Get(ctx, environmentID, vmID, id)
+	if err != nil {
+		return fmt.Errorf("error retrieving interface (%s): %v", id, err)
+	}
+	path := fmt.Sprintf("v2/configurations/%s/vms/%s/interfaces/%s/secondary_ips", environmentID, vmID, id)
+
+	if remove != nil {
+		for _, secondaryIP := range networkInterface.SecondaryIPs {
+			if secondaryIP.ID == nil || secondaryIP.Address == nil || !remove.Contains(*secondaryIP.Address) {
+				continue
+			}
+			log.Printf("[INFO] removing interface (%s) secondary IP: %s", id, *secondaryIP.Address)
+			err = apiClient.do(ctx, "DELETE", fmt.Sprintf("%s/%s.json", path, *secondaryIP.ID), nil, nil)
+			if err != nil && !utils.ResponseErrorIsNotFound(err) {
+				return fmt.Errorf("error removing interface (%s) secondary IP (%s): %v", id, *secondaryIP.Address, err)
+			}
+			log.Printf("[INFO] removed interface (%s) secondary IP: %s", id, *secondaryIP.Address)
+		}
+	}
+
+	if add == nil || add.Len() == 0 {
+		return nil
+	}
+	ips := expandStringSet(add)
+	if networkInterface.NetworkSubnet != nil {
+		if err = checkIPsInSubnet(ips, *networkInterface.NetworkSubnet); err != nil {
+			return err
+		}
+	}
+	for _, ip := range ips {
+		log.Printf("[INFO] adding interface (%s) secondary IP: %s", id, ip)
+		err = apiClient.do(ctx, "POST", path+".json", &secondaryIPRequest{IP: utils.String(ip)}, nil)
+		if err != nil {
+			return fmt.Errorf("error adding interface (%s) secondary IP (%s): %v", id, ip, err)
+		}
+		log.Printf("[INFO] added interface (%s) secondary IP: %s", id, ip)
+	}
+	return nil
+}
+
+// secondaryIPRequest adds a secondary IP address to a network interface, which is not supported by the SDK
+type secondaryIPRequest struct {
+	IP *string `json:"ip"`
+}
+
+// checkIPsInSubnet returns an error if any of the IP addresses is outside the subnet, given in CIDR notation
+func checkIPsInSubnet(ips []string, subnet string) error {
+	_, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return fmt.Errorf("error parsing subnet (%s): %v", subnet, err)
+	}
+	for _, ip := range ips {
+		if !ipNet.Contains(net.ParseIP(ip)) {
+			return fmt.Errorf("IP (%s) is not within the subnet (%s) of the network", ip, subnet)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package skytap
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccSkytapNetworkInterface_Basic(t *testing.T) {
+	templateID, vmID, newEnvTemplateID := setupEnvironment()
+	uniqueSuffixEnv := acctest.RandInt()
+	var networkInterface skytap.Interface
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapNetworkInterfaceConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID,
+					"skytap_network.first", "10.0.4.10", "myhost"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkInterfaceExists("skytap_network_interface.baz", &networkInterface),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "nic_type", "vmxnet3"),
+					resource.TestCheckResourceAttrPair("skytap_network_interface.baz", "network_id", "skytap_network.first", "id"),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "ip", "10.0.4.10"),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "hostname", "myhost"),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "network_subnet", "10.0.4.0/24"),
+					resource.TestCheckResourceAttrSet("skytap_network_interface.baz", "mac"),
+				),
+			},
+			{
+				Config: testAccSkytapNetworkInterfaceConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID,
+					"skytap_network.second", "10.0.5.20", "otherhost"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkInterfaceExists("skytap_network_interface.baz", &networkInterface),
+					resource.TestCheckResourceAttrPair("skytap_network_interface.baz", "network_id", "skytap_network.second", "id"),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "ip", "10.0.5.20"),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "hostname", "otherhost"),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "network_subnet", "10.0.5.0/24"),
+				),
+			},
+			{
+				ResourceName:      "skytap_network_interface.baz",
+				ImportState:       true,
+				ImportStateIdFunc: testAccSkytapVMChildImportStateIDFunc("skytap_network_interface.baz"),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccSkytapNetworkInterface_SecondaryIPs(t *testing.T) {
+	templateID, vmID, newEnvTemplateID := setupEnvironment()
+	uniqueSuffixEnv := acctest.RandInt()
+	var networkInterface skytap.Interface
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapNetworkInterfaceSecondaryIPsConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID,
+					`"10.0.4.100", "10.0.4.101"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkInterfaceExists("skytap_network_interface.baz", &networkInterface),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "secondary_ips.#", "2"),
+					resource.TestCheckTypeSetElemAttr("skytap_network_interface.baz", "secondary_ips.*", "10.0.4.100"),
+					resource.TestCheckTypeSetElemAttr("skytap_network_interface.baz", "secondary_ips.*", "10.0.4.101"),
+				),
+			},
+			{
+				Config: testAccSkytapNetworkInterfaceSecondaryIPsConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID,
+					`"10.0.4.101", "10.0.4.102"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkInterfaceExists("skytap_network_interface.baz", &networkInterface),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "secondary_ips.#", "2"),
+					resource.TestCheckTypeSetElemAttr("skytap_network_interface.baz", "secondary_ips.*", "10.0.4.101"),
+					resource.TestCheckTypeSetElemAttr("skytap_network_interface.baz", "secondary_ips.*", "10.0.4.102"),
+				),
+			},
+			{
+				Config: testAccSkytapNetworkInterfaceSecondaryIPsConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID,
+					`"10.0.5.100"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`is not within the subnet`),
+			},
+			{
+				Config: testAccSkytapNetworkInterfaceConfigBlock(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID,
+					"skytap_network.second", "10.0.4.10", "myhost", `secondary_ips  = ["10.0.4.101"]`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`is not within the subnet`),
+			},
+			{
+				Config: testAccSkytapNetworkInterfaceConfigBlock(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID,
+					"skytap_network.second", "10.0.5.10", "myhost", `secondary_ips  = ["10.0.5.100", "10.0.5.101"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkInterfaceExists("skytap_network_interface.baz", &networkInterface),
+					resource.TestCheckResourceAttrPair("skytap_network_interface.baz", "network_id", "skytap_network.second", "id"),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "ip", "10.0.5.10"),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "secondary_ips.#", "2"),
+					resource.TestCheckTypeSetElemAttr("skytap_network_interface.baz", "secondary_ips.*", "10.0.5.100"),
+					resource.TestCheckTypeSetElemAttr("skytap_network_interface.baz", "secondary_ips.*", "10.0.5.101"),
+				),
+			},
+			{
+				Config: testAccSkytapNetworkInterfaceSecondaryIPsConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkInterfaceExists("skytap_network_interface.baz", &networkInterface),
+					resource.TestCheckResourceAttr("skytap_network_interface.baz", "secondary_ips.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestCheckIPsInSubnet(t *testing.T) {
+	assert.NoError(t, checkIPsInSubnet([]string{"10.0.4.1", "10.0.4.254"}, "10.0.4.0/24"))
+	assert.NoError(t, checkIPsInSubnet(nil, "10.0.4.0/24"))
+	assert.EqualError(t, checkIPsInSubnet([]string{"10.0.4.1", "10.0.5.1"}, "10.0.4.0/24"),
+		"IP (10.0.5.1) is not within the subnet (10.0.4.0/24) of the network")
+	assert.Error(t, checkIPsInSubnet([]string{"10.0.4.1"}, "10.0.4.0"))
+}
+
+func TestNetworkInterfaceUpdateRequest(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceSkytapNetworkInterface().Schema, map[string]interface{}{
+		"environment_id": "123",
+		"vm_id":          "456",
+		"network_id":     "789",
+		"hostname":       "myhost",
+	})
+
+	opts, requiresUpdate := networkInterfaceUpdateRequest(d)
+	assert.True(t, requiresUpdate)
+	assert.Nil(t, opts.IP)
+	assert.Equal(t, "myhost", *opts.Hostname)
+
+	d = schema.TestResourceDataRaw(t, resourceSkytapNetworkInterface().Schema, map[string]interface{}{
+		"environment_id": "123",
+		"vm_id":          "456",
+		"network_id":     "789",
+	})
+	_, requiresUpdate = networkInterfaceUpdateRequest(d)
+	assert.False(t, requiresUpdate)
+
+	// moving the interface to another network sends the unchanged IP address again
+	sm := schema.InternalMap(resourceSkytapNetworkInterface().Schema)
+	state := &terraform.InstanceState{
+		ID: "nic-1-2-1",
+		Attributes: map[string]string{
+			"id":              "nic-1-2-1",
+			"environment_id":  "123",
+			"vm_id":           "456",
+			"network_id":      "789",
+			"ip":              "10.0.4.10",
+			"hostname":        "myhost",
+			"secondary_ips.#": "0",
+		},
+	}
+	diff, err := sm.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"environment_id": "123",
+		"vm_id":          "456",
+		"network_id":     "790",
+		"ip":             "10.0.4.10",
+		"hostname":       "myhost",
+	}), nil, nil, true)
+	assert.NoError(t, err)
+	d, err = sm.Data(state, diff)
+	assert.NoError(t, err)
+
+	opts, requiresUpdate = networkInterfaceUpdateRequest(d)
+	assert.True(t, requiresUpdate)
+	assert.Equal(t, "10.0.4.10", *opts.IP)
+	assert.Nil(t, opts.Hostname)
+}
+
+func testAccCheckSkytapNetworkInterfaceExists(name string, networkInterface *skytap.Interface) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, err := getResource(s, name)
+		if err != nil {
+			return err
+		}
+
+		client := testAccProvider.Meta().(*SkytapClient).interfacesClient
+		ctx := context.TODO()
+		found, err := client.Get(ctx, rs.Primary.Attributes["environment_id"], rs.Primary.Attributes["vm_id"], rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("interface (%s) was not found: %v", rs.Primary.ID, err)
+		}
+
+		*networkInterface = *found
+		return nil
+	}
+}
+
+func testAccSkytapVMChildImportStateIDFunc(name string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, err := getResource(s, name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s/%s/%s", rs.Primary.Attributes["environment_id"], rs.Primary.Attributes["vm_id"],
+			rs.Primary.ID), nil
+	}
+}
+
+func testAccSkytapNetworkInterfaceConfig(envTemplateID string, uniqueSuffixEnv int, templateID string, vmID string,
+	network string, ip string, hostname string) string {
+	return testAccSkytapNetworkInterfaceConfigBlock(envTemplateID, uniqueSuffixEnv, templateID, vmID, network, ip, hostname, "")
+}
+
+func testAccSkytapNetworkInterfaceSecondaryIPsConfig(envTemplateID string, uniqueSuffixEnv int, templateID string,
+	vmID string, secondaryIPs string) string {
+	return testAccSkytapNetworkInterfaceConfigBlock(envTemplateID, uniqueSuffixEnv, templateID, vmID,
+		"skytap_network.first", "10.0.4.10", "myhost", fmt.Sprintf("secondary_ips  = [%s]", secondaryIPs))
+}
+
+func testAccSkytapNetworkInterfaceConfigBlock(envTemplateID string, uniqueSuffixEnv int, templateID string, vmID string,
+	network string, ip string, hostname string, block string) string {
+	return testAccSkytapVMConfigBlock(envTemplateID, uniqueSuffixEnv, templateID, vmID, "bar", `
+	resource "skytap_network" "first" {
+		environment_id = "${skytap_environment.foo.id}"
+		name           = "tftest-network-1"
+		domain         = "first.skytap.io"
+		subnet         = "10.0.4.0/24"
+	}
+
+	resource "skytap_network" "second" {
+		environment_id = "${skytap_environment.foo.id}"
+		name           = "tftest-network-2"
+		domain         = "second.skytap.io"
+		subnet         = "10.0.5.0/24"
+	}`, "") + fmt.Sprintf(`
+	resource "skytap_network_interface" "baz" {
+		environment_id = "${skytap_environment.foo.id}"
+		vm_id          = "${skytap_vm.bar.id}"
+		nic_type       = "vmxnet3"
+		network_id     = "${%s.id}"
+		ip             = "%s"
+		hostname       = "%s"
+		%s
+	}`, network, ip, hostname, block)
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
 )
@@ -75,6 +78,12 @@ func TestAccSkytapNetwork_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("skytap_network.bar", "tunnelable", "true"),
 				),
 			},
+			{
+				ResourceName:      "skytap_network.bar",
+				ImportState:       true,
+				ImportStateIdFunc: testAccSkytapEnvironmentChildImportStateIDFunc("skytap_network.bar"),
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -117,6 +126,312 @@ func TestAccSkytapNetwork_Update(t *testing.T) {
 	})
 }
 
+func TestAccSkytapNetwork_Manual(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+	uniqueSuffixEnv := acctest.RandInt()
+	uniqueSuffixNet := acctest.RandInt()
+	var network skytap.Network
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapNetworkConfig_block(templateID, uniqueSuffixEnv, uniqueSuffixNet, `
+		network_type = "automatic"
+		subnet       = "192.168.1.0/24"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`domain is required for automatic networks`),
+			},
+			{
+				Config: testAccSkytapNetworkConfig_block(templateID, uniqueSuffixEnv, uniqueSuffixNet, `
+		network_type = "manual"
+		subnet       = "192.168.1.0/24"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkExists("skytap_environment.foo", "skytap_network.bar", &network),
+					resource.TestCheckResourceAttr("skytap_network.bar", "network_type", "manual"),
+					resource.TestCheckResourceAttr("skytap_network.bar", "subnet", "192.168.1.0/24"),
+				),
+			},
+			{
+				ResourceName:      "skytap_network.bar",
+				ImportState:       true,
+				ImportStateIdFunc: testAccSkytapEnvironmentChildImportStateIDFunc("skytap_network.bar"),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccSkytapNetwork_Nameservers(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+	uniqueSuffixEnv := acctest.RandInt()
+	uniqueSuffixNet := acctest.RandInt()
+	var network skytap.Network
+	var networkUpdated skytap.Network
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapNetworkConfig_block(templateID, uniqueSuffixEnv, uniqueSuffixNet, `
+		domain             = "skytap.io"
+		subnet             = "192.168.1.0/24"
+		primary_nameserver = "8.8.8.8"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkExists("skytap_environment.foo", "skytap_network.bar", &network),
+					resource.TestCheckResourceAttr("skytap_network.bar", "network_type", "automatic"),
+					resource.TestCheckResourceAttr("skytap_network.bar", "primary_nameserver", "8.8.8.8"),
+					resource.TestCheckResourceAttr("skytap_network.bar", "secondary_nameserver", ""),
+				),
+			},
+			{
+				Config: testAccSkytapNetworkConfig_block(templateID, uniqueSuffixEnv, uniqueSuffixNet, `
+		domain               = "skytap.io"
+		subnet               = "192.168.1.0/24"
+		primary_nameserver   = "1.1.1.1"
+		secondary_nameserver = "8.8.4.4"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkExists("skytap_environment.foo", "skytap_network.bar", &networkUpdated),
+					testAccCheckSkytapNetworkNotRecreated(&network, &networkUpdated),
+					resource.TestCheckResourceAttr("skytap_network.bar", "primary_nameserver", "1.1.1.1"),
+					resource.TestCheckResourceAttr("skytap_network.bar", "secondary_nameserver", "8.8.4.4"),
+				),
+			},
+			{
+				Config: testAccSkytapNetworkConfig_block(templateID, uniqueSuffixEnv, uniqueSuffixNet, `
+		domain             = "skytap.io"
+		subnet             = "192.168.1.0/24"
+		primary_nameserver = "1.1.1.1"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkExists("skytap_environment.foo", "skytap_network.bar", &networkUpdated),
+					testAccCheckSkytapNetworkNotRecreated(&network, &networkUpdated),
+					resource.TestCheckResourceAttr("skytap_network.bar", "secondary_nameserver", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkytapNetwork_AdoptExisting(t *testing.T) {
+	templateID := utils.GetEnv("SKYTAP_TEMPLATE_ID", "1478959")
+	uniqueSuffixEnv := acctest.RandInt()
+	uniqueSuffixNet := acctest.RandInt()
+	var network skytap.Network
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapNetworkConfig_block(templateID, uniqueSuffixEnv, uniqueSuffixNet, `
+		domain         = "adopted.skytap.io"
+		subnet         = "${skytap_environment.foo.networks.0.subnet}"
+		tunnelable     = true
+		adopt_existing = "subnet"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapNetworkExists("skytap_environment.foo", "skytap_network.bar", &network),
+					resource.TestCheckResourceAttrPair("skytap_network.bar", "id", "skytap_environment.foo", "networks.0.id"),
+					resource.TestCheckResourceAttr("skytap_network.bar", "name", fmt.Sprintf("tftest-network-%d", uniqueSuffixNet)),
+					resource.TestCheckResourceAttr("skytap_network.bar", "domain", "adopted.skytap.io"),
+					resource.TestCheckResourceAttr("skytap_network.bar", "tunnelable", "true"),
+					resource.TestCheckResourceAttr("skytap_network.bar", "keep_on_destroy", "true"),
+				),
+			},
+			{
+				// removing the resource keeps the adopted network in the environment
+				Config: testAccSkytapNetworkConfig_environment(templateID, uniqueSuffixEnv),
+				Check:  testAccCheckSkytapNetworkKept("skytap_environment.foo", &network),
+			},
+		},
+	})
+}
+
+func TestFindAdoptableNetwork(t *testing.T) {
+	networks := []skytap.Network{
+		{ID: utils.String("1"), Name: utils.String("Default Network"), Subnet: utils.String("10.0.0.0/24")},
+		{ID: utils.String("2"), Name: utils.String("backend"), Subnet: utils.String("10.0.1.0/24")},
+		{ID: utils.String("3"), Name: utils.String("backend"), Subnet: utils.String("10.0.2.0/24")},
+	}
+
+	network, err := findAdoptableNetwork(networks, "name", "Default Network")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", *network.ID)
+
+	network, err = findAdoptableNetwork(networks, "subnet", "10.0.2.0/24")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", *network.ID)
+
+	_, err = findAdoptableNetwork(networks, "name", "backend")
+	assert.EqualError(t, err, "more than one network with the name (backend) was found")
+
+	_, err = findAdoptableNetwork(networks, "subnet", "10.0.3.0/24")
+	assert.EqualError(t, err, "no network with the subnet (10.0.3.0/24) was found")
+}
+
+func TestResourceSkytapNetworkAdoptExistingDiff(t *testing.T) {
+	r := resourceSkytapNetwork()
+	config := func(adoptExisting string) *terraform.ResourceConfig {
+		raw := map[string]interface{}{
+			"environment_id": "123",
+			"name":           "network",
+			"domain":         "example.com",
+			"subnet":         "10.0.0.0/24",
+		}
+		if adoptExisting != "" {
+			raw["adopt_existing"] = adoptExisting
+		}
+		return terraform.NewResourceConfigRaw(raw)
+	}
+
+	// keep_on_destroy is left unknown on create, to default on whether the network is adopted
+	diff, err := schema.InternalMap(r.Schema).Diff(context.Background(), nil, config("subnet"), nil, nil, true)
+	assert.NoError(t, err)
+	d, err := schema.InternalMap(r.Schema).Data(nil, diff)
+	assert.NoError(t, err)
+	_, ok := d.GetOkExists("keep_on_destroy")
+	assert.False(t, ok)
+
+	existing := r.Data(nil)
+	existing.SetId("456")
+	for k, v := range map[string]interface{}{
+		"environment_id":  "123",
+		"name":            "network",
+		"network_type":    "automatic",
+		"domain":          "example.com",
+		"subnet":          "10.0.0.0/24",
+		"tunnelable":      false,
+		"adopt_existing":  "subnet",
+		"keep_on_destroy": true,
+	} {
+		assert.NoError(t, existing.Set(k, v))
+	}
+	diff, err = schema.InternalMap(r.Schema).Diff(context.Background(), existing.State(), config("name"), nil, nil, true)
+	assert.NoError(t, err)
+	assert.True(t, diff.RequiresNew())
+
+	// an imported or created network is not adopted again
+	imported := r.Data(nil)
+	imported.SetId("456")
+	for k, v := range map[string]interface{}{
+		"environment_id":  "123",
+		"name":            "network",
+		"network_type":    "automatic",
+		"domain":          "example.com",
+		"subnet":          "10.0.0.0/24",
+		"tunnelable":      false,
+		"keep_on_destroy": false,
+	} {
+		assert.NoError(t, imported.Set(k, v))
+	}
+	diff, err = schema.InternalMap(r.Schema).Diff(context.Background(), imported.State(), config("subnet"), nil, nil, true)
+	assert.NoError(t, err)
+	assert.Nil(t, diff)
+}
+
+func TestResourceSkytapNetworkTypeDiff(t *testing.T) {
+	r := resourceSkytapNetwork()
+	config := func(networkType string, domain string) *terraform.ResourceConfig {
+		raw := map[string]interface{}{
+			"environment_id": "123",
+			"name":           "network",
+			"network_type":   networkType,
+			"subnet":         "10.0.0.0/24",
+		}
+		if domain != "" {
+			raw["domain"] = domain
+		}
+		return terraform.NewResourceConfigRaw(raw)
+	}
+
+	_, err := r.Diff(context.Background(), nil, config("manual", "example.com"), nil)
+	assert.EqualError(t, err, "domain cannot be set for manual networks")
+
+	_, err = r.Diff(context.Background(), nil, config("manual", ""), nil)
+	assert.NoError(t, err)
+
+	// the domain kept by a network becoming manual is not rejected
+	existing := r.Data(nil)
+	existing.SetId("456")
+	for k, v := range map[string]interface{}{
+		"environment_id":  "123",
+		"name":            "network",
+		"network_type":    "automatic",
+		"domain":          "example.com",
+		"subnet":          "10.0.0.0/24",
+		"tunnelable":      false,
+		"keep_on_destroy": false,
+	} {
+		assert.NoError(t, existing.Set(k, v))
+	}
+	_, err = r.Diff(context.Background(), existing.State(), config("manual", ""), nil)
+	assert.NoError(t, err)
+}
+
+func TestImportStateNetwork(t *testing.T) {
+	d := resourceSkytapNetwork().TestResourceData()
+
+	d.SetId("123/456")
+	_, err := importStateNetwork(context.TODO(), d, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "456", d.Id())
+	assert.Equal(t, "123", d.Get("environment_id"))
+	v, ok := d.GetOkExists("keep_on_destroy")
+	assert.True(t, ok)
+	assert.Equal(t, false, v)
+}
+
+func TestExpandNetworkSettings(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceSkytapNetwork().Schema, map[string]interface{}{
+		"environment_id":     "123",
+		"name":               "network",
+		"network_type":       "manual",
+		"subnet":             "10.0.0.0/24",
+		"primary_nameserver": "8.8.8.8",
+	})
+
+	opts := expandNetworkSettings(d, func(key string) bool { return key == "primary_nameserver" })
+	assert.NotNil(t, opts)
+	assert.Nil(t, opts.NetworkType)
+	assert.Equal(t, "8.8.8.8", *opts.PrimaryNameserver)
+	assert.Nil(t, opts.SecondaryNameserver)
+
+	opts = expandNetworkSettings(d, func(key string) bool { return key != "primary_nameserver" })
+	assert.Equal(t, "manual", *opts.NetworkType)
+	assert.Equal(t, "", *opts.SecondaryNameserver)
+
+	assert.Nil(t, expandNetworkSettings(d, func(string) bool { return false }))
+}
+
+func testAccCheckSkytapNetworkNotRecreated(network *skytap.Network, networkUpdated *skytap.Network) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if *network.ID != *networkUpdated.ID {
+			return fmt.Errorf("network (%s) was recreated as (%s)", *network.ID, *networkUpdated.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckSkytapNetworkKept(environmentName string, network *skytap.Network) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, err := getResource(s, environmentName)
+		if err != nil {
+			return err
+		}
+
+		client := testAccProvider.Meta().(*SkytapClient).networksClient
+		_, err = client.Get(context.TODO(), rs.Primary.ID, *network.ID)
+		if err != nil {
+			return fmt.Errorf("network (%s) was not kept: %v", *network.ID, err)
+		}
+		return nil
+	}
+}
+
 func testAccCheckSkytapNetworkExists(environmentName string, networkName string, network *skytap.Network) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 
@@ -163,6 +478,32 @@ func testAccSkytapNetworkConfig_basic(templateID string, uniqueSuffixEnv int, un
 `, templateID, networkEnvironmentPrefix, uniqueSuffixEnv, uniqueSuffixNet, domain, subnet, gateway, tunnelable)
 }
 
+func testAccSkytapNetworkConfig_environment(templateID string, uniqueSuffixEnv int) string {
+	return fmt.Sprintf(`
+	resource "skytap_environment" "foo" {
+		template_id = "%s"
+		name 		= "%s-environment-%d"
+		description = "This is an environment to support a network skytap terraform provider acceptance test"
+	}
+`, templateID, networkEnvironmentPrefix, uniqueSuffixEnv)
+}
+
+func testAccSkytapNetworkConfig_block(templateID string, uniqueSuffixEnv int, uniqueSuffixNet int, block string) string {
+	return fmt.Sprintf(`
+	resource "skytap_environment" "foo" {
+		template_id = "%s"
+		name 		= "%s-environment-%d"
+		description = "This is an environment to support a network skytap terraform provider acceptance test"
+	}
+
+	resource "skytap_network" "bar" {
+		name           = "tftest-network-%d"
+		environment_id = "${skytap_environment.foo.id}"
+		%s
+	}
+`, templateID, networkEnvironmentPrefix, uniqueSuffixEnv, uniqueSuffixNet, block)
+}
+
 func getNetwork(rs *terraform.ResourceState, environmentID string) (*skytap.Network, error) {
 	var err error
 	// retrieve the connection established in Provider configuration
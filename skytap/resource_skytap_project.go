@@ -21,6 +21,9 @@ func resourceSkytapProject() *schema.Resource {
 		ReadContext:   resourceSkytapProjectRead,
 		UpdateContext: resourceSkytapProjectUpdate,
 		DeleteContext: resourceSkytapProjectDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateIntID("project"),
+		},
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
@@ -153,11 +156,11 @@ func resourceSkytapProjectRead(ctx context.Context, d *schema.ResourceData, meta
 		return diag.FromErr(err)
 	}
 
-	environments, err := client.ListEnvironments(ctx, id)
+	environments, err := listProjectEnvironments(ctx, meta, id)
 	if err != nil {
 		return diag.Errorf("error retrieving project environments: %v", err)
 	}
-	err = d.Set("environment_ids", flattenProjectEnvironments(environments.Value))
+	err = d.Set("environment_ids", flattenProjectEnvironments(environments))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -66,6 +66,11 @@ func TestAccSkytapProject_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("skytap_project.foo", "show_project_members", "true"),
 				),
 			},
+			{
+				ResourceName:      "skytap_project.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -0,0 +1,164 @@
+package skytap
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func resourceSkytapPublicIP() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSkytapPublicIPCreate,
+		ReadContext:   resourceSkytapPublicIPRead,
+		DeleteContext: resourceSkytapPublicIPDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The Skytap region the public IP address is reserved in, such as `US-West`. It can only be attached to VMs in the same region",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			// computed attributes
+			"address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The public IP address",
+			},
+
+			"dns_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The DNS name of the public IP address, if any",
+			},
+		},
+	}
+}
+
+// publicIP is a public IP address reserved by the customer, which is not supported by the SDK
+type publicIP struct {
+	ID      *string `json:"id"`
+	Address *string `json:"address"`
+	Region  *string `json:"region"`
+	DNSName *string `json:"dns_name"`
+}
+
+// publicIPRequest acquires a public IP address in a region, or attaches one to a network interface
+type publicIPRequest struct {
+	Region *string `json:"region,omitempty"`
+	IP     *string `json:"ip,omitempty"`
+}
+
+func resourceSkytapPublicIPCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).apiClient
+
+	opts := publicIPRequest{
+		Region: utils.String(d.Get("region").(string)),
+	}
+
+	log.Printf("[INFO] acquiring public IP")
+	log.Printf("[TRACE] acquiring public IP: %v", spew.Sdump(opts))
+	var ip publicIP
+	if err := client.do(ctx, "POST", "ips/acquire.json", &opts, &ip); err != nil {
+		return diag.Errorf("error acquiring public IP: %v", err)
+	}
+
+	if ip.ID == nil {
+		return diag.Errorf("public IP ID is not set")
+	}
+	d.SetId(*ip.ID)
+
+	log.Printf("[INFO] acquired public IP: %s", *ip.ID)
+	log.Printf("[TRACE] acquired public IP: %v", spew.Sdump(ip))
+
+	return resourceSkytapPublicIPRead(ctx, d, meta)
+}
+
+func resourceSkytapPublicIPRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id := d.Id()
+
+	log.Printf("[INFO] retrieving public IP: %s", id)
+	ip, err := getPublicIP(ctx, meta, id)
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] public IP (%s) was not found - removing from state", id)
+			d.SetId("")
+			return nil
+		}
+
+		return diag.Errorf("error retrieving public IP (%s): %v", id, err)
+	}
+
+	err = d.Set("region", ip.Region)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("address", ip.Address)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("dns_name", ip.DNSName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] public IP retrieved: %s", id)
+	log.Printf("[TRACE] public IP retrieved: %v", spew.Sdump(ip))
+
+	return nil
+}
+
+func resourceSkytapPublicIPDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).apiClient
+
+	id := d.Id()
+
+	log.Printf("[INFO] releasing public IP: %s", id)
+	err := client.do(ctx, "POST", fmt.Sprintf("ips/%s/release.json", id), nil, nil)
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] public IP (%s) was not found - assuming released", id)
+			return nil
+		}
+
+		return diag.Errorf("error releasing public IP (%s): %v", id, err)
+	}
+
+	log.Printf("[INFO] public IP released: %s", id)
+
+	return nil
+}
+
+func getPublicIP(ctx context.Context, meta interface{}, id string) (*publicIP, error) {
+	var ip publicIP
+	if err := meta.(*SkytapClient).apiClient.do(ctx, "GET", fmt.Sprintf("ips/%s.json", id), nil, &ip); err != nil {
+		return nil, err
+	}
+	return &ip, nil
+}
+
+// findPublicIPByAddress returns the public IP of the customer with the address, or nil if there is none
+func findPublicIPByAddress(ctx context.Context, meta interface{}, address string) (*publicIP, error) {
+	var ips []publicIP
+	if err := meta.(*SkytapClient).apiClient.do(ctx, "GET", "ips.json", nil, &ips); err != nil {
+		return nil, err
+	}
+	for idx := range ips {
+		if ips[idx].Address != nil && *ips[idx].Address == address {
+			return &ips[idx], nil
+		}
+	}
+	return nil, nil
+}
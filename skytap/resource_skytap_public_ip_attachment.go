@@ -0,0 +1,248 @@
+package skytap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/skytap/skytap-sdk-go/skytap"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func resourceSkytapPublicIPAttachment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSkytapPublicIPAttachmentCreate,
+		ReadContext:   resourceSkytapPublicIPAttachmentRead,
+		DeleteContext: resourceSkytapPublicIPAttachmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateInterfaceChild,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"environment_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the environment containing the VM",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"vm_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the VM containing the network interface",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"interface_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the network interface the public IP address is attached to",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"public_ip_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the public IP address to attach",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			// computed attributes
+			"address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The public IP address the network interface is reachable on",
+			},
+
+			"dns_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The DNS name of the public IP address, if any",
+			},
+		},
+	}
+}
+
+func resourceSkytapPublicIPAttachmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).apiClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	interfaceID := d.Get("interface_id").(string)
+	publicIPID := d.Get("public_ip_id").(string)
+
+	ip, err := getPublicIP(ctx, meta, publicIPID)
+	if err != nil {
+		return diag.Errorf("error retrieving public IP (%s): %v", publicIPID, err)
+	}
+	if ip.Address == nil {
+		return diag.Errorf("public IP (%s) address is not set", publicIPID)
+	}
+	opts := publicIPRequest{
+		IP: ip.Address,
+	}
+
+	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] attaching public IP (%s) to interface: %s", publicIPID, interfaceID)
+	log.Printf("[TRACE] attaching public IP: %v", spew.Sdump(opts))
+	path := fmt.Sprintf("configurations/%s/vms/%s/interfaces/%s/ips.json", environmentID, vmID, interfaceID)
+	if err = client.do(ctx, "POST", path, &opts, nil); err != nil {
+		return diag.Errorf("error attaching public IP (%s) to interface (%s): %v", publicIPID, interfaceID, err)
+	}
+
+	networkInterface, err := meta.(*SkytapClient).interfacesClient.Get(ctx, environmentID, vmID, interfaceID)
+	if err != nil {
+		return diag.Errorf("error retrieving interface (%s): %v", interfaceID, err)
+	}
+	attachment := findPublicIPAttachment(networkInterface, func(attachment skytap.PublicIPAttachment) bool {
+		return attachment.Address != nil && *attachment.Address == *ip.Address
+	})
+	if attachment == nil || attachment.ID == nil {
+		return diag.Errorf("error finding the attachment of public IP (%s) to interface (%s)", publicIPID, interfaceID)
+	}
+	d.SetId(strconv.Itoa(*attachment.ID))
+
+	log.Printf("[INFO] attached public IP: %s", d.Id())
+	log.Printf("[TRACE] attached public IP: %v", spew.Sdump(attachment))
+
+	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSkytapPublicIPAttachmentRead(ctx, d, meta)
+}
+
+func resourceSkytapPublicIPAttachmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).interfacesClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	interfaceID := d.Get("interface_id").(string)
+	id := d.Id()
+
+	log.Printf("[INFO] retrieving public IP attachment: %s", id)
+	networkInterface, err := client.Get(ctx, environmentID, vmID, interfaceID)
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] interface (%s) was not found - removing public IP attachment (%s) from state", interfaceID, id)
+			d.SetId("")
+			return nil
+		}
+
+		return diag.Errorf("error retrieving interface (%s): %v", interfaceID, err)
+	}
+
+	attachment := findPublicIPAttachment(networkInterface, func(attachment skytap.PublicIPAttachment) bool {
+		return attachment.ID != nil && strconv.Itoa(*attachment.ID) == id
+	})
+	if attachment == nil {
+		log.Printf("[DEBUG] public IP attachment (%s) was not found - removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	err = d.Set("environment_id", environmentID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("vm_id", vmID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("interface_id", interfaceID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// the public IP key is the ID of the public IP. It is only set when the API returns it, as any other value would
+	// replace the attachment. Once imported, the public IP is otherwise found by its address.
+	if attachment.PublicIPKey != nil && *attachment.PublicIPKey != "" {
+		err = d.Set("public_ip_id", attachment.PublicIPKey)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	} else if d.Get("public_ip_id").(string) == "" && attachment.Address != nil {
+		ip, err := findPublicIPByAddress(ctx, meta, *attachment.Address)
+		if err != nil {
+			return diag.Errorf("error retrieving public IP (%s): %v", *attachment.Address, err)
+		}
+		if ip != nil && ip.ID != nil {
+			err = d.Set("public_ip_id", ip.ID)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+	err = d.Set("address", attachment.Address)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("dns_name", attachment.DNSName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] public IP attachment retrieved: %s", id)
+	log.Printf("[TRACE] public IP attachment retrieved: %v", spew.Sdump(attachment))
+
+	return nil
+}
+
+func resourceSkytapPublicIPAttachmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).apiClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	interfaceID := d.Get("interface_id").(string)
+	address := d.Get("address").(string)
+	id := d.Id()
+
+	log.Printf("[INFO] detaching public IP: %s", id)
+	path := fmt.Sprintf("configurations/%s/vms/%s/interfaces/%s/ips/%s.json", environmentID, vmID, interfaceID, address)
+	err := client.do(ctx, "DELETE", path, nil, nil)
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] public IP attachment (%s) was not found - assuming removed", id)
+			return nil
+		}
+
+		return diag.Errorf("error detaching public IP (%s): %v", id, err)
+	}
+	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutDelete); err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] public IP detached: %s", id)
+
+	return nil
+}
+
+// findPublicIPAttachment returns the first public IP attachment of the network interface matching the filter,
+// or nil if there is none
+func findPublicIPAttachment(networkInterface *skytap.Interface,
+	filter func(skytap.PublicIPAttachment) bool) *skytap.PublicIPAttachment {
+	for idx := range networkInterface.PublicIPAttachments {
+		if filter(networkInterface.PublicIPAttachments[idx]) {
+			return &networkInterface.PublicIPAttachments[idx]
+		}
+	}
+	return nil
+}
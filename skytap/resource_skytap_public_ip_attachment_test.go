@@ -0,0 +1,160 @@
+package skytap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func TestAccSkytapPublicIPAttachment_Basic(t *testing.T) {
+	templateID, vmID, newEnvTemplateID := setupEnvironment()
+	uniqueSuffixEnv := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckSkytapEnvironmentDestroy,
+			testAccCheckSkytapPublicIPDestroy,
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapPublicIPAttachmentConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapPublicIPAttachmentExists("skytap_public_ip_attachment.demo"),
+					resource.TestCheckResourceAttrPair("skytap_public_ip_attachment.demo", "public_ip_id", "skytap_public_ip.demo", "id"),
+					resource.TestCheckResourceAttrPair("skytap_public_ip_attachment.demo", "address", "skytap_public_ip.demo", "address"),
+				),
+			},
+			{
+				ResourceName:      "skytap_public_ip_attachment.demo",
+				ImportState:       true,
+				ImportStateIdFunc: testAccSkytapInterfaceChildImportStateIDFunc("skytap_public_ip_attachment.demo"),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestFindPublicIPAttachment(t *testing.T) {
+	networkInterface := &skytap.Interface{
+		PublicIPAttachments: []skytap.PublicIPAttachment{
+			{ID: utils.Int(1), Address: utils.String("203.0.113.10"), PublicIPKey: utils.String("10")},
+			{ID: utils.Int(2), Address: utils.String("203.0.113.11"), PublicIPKey: utils.String("11")},
+		},
+	}
+
+	attachment := findPublicIPAttachment(networkInterface, func(attachment skytap.PublicIPAttachment) bool {
+		return *attachment.Address == "203.0.113.11"
+	})
+	assert.NotNil(t, attachment)
+	assert.Equal(t, 2, *attachment.ID)
+
+	attachment = findPublicIPAttachment(networkInterface, func(attachment skytap.PublicIPAttachment) bool {
+		return *attachment.ID == 3
+	})
+	assert.Nil(t, attachment)
+}
+
+func TestResourceSkytapPublicIPAttachmentRead(t *testing.T) {
+	var ip publicIP
+	err := json.Unmarshal(readTestFile(t, "public_ip_response.json"), &ip)
+	assert.NoError(t, err)
+
+	response := readTestFile(t, "vm_interface_public_ip_attachments_response.json")
+	ipsResponse := readTestFile(t, "public_ips_response.json")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ips.json" {
+			_, _ = w.Write(ipsResponse)
+			return
+		}
+		assert.Equal(t, "/v2/configurations/123/vms/456/interfaces/nic-20246343-38367563-0", r.URL.Path)
+		_, _ = w.Write(response)
+	}))
+	defer server.Close()
+	client, err := skytap.NewClient(skytap.NewDefaultSettings(
+		skytap.WithBaseURL(server.URL+"/"),
+		skytap.WithCredentialsProvider(skytap.NewAPITokenCredentials("user", "token")),
+	))
+	assert.NoError(t, err)
+	meta := &SkytapClient{interfacesClient: client.Interfaces, apiClient: newAPIClient(client, 3)}
+
+	read := func(id string, publicIPID string) *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceSkytapPublicIPAttachment().Schema, map[string]interface{}{
+			"environment_id": "123",
+			"vm_id":          "456",
+			"interface_id":   "nic-20246343-38367563-0",
+			"public_ip_id":   publicIPID,
+		})
+		d.SetId(id)
+		assert.False(t, resourceSkytapPublicIPAttachmentRead(context.Background(), d, meta).HasError())
+		return d
+	}
+
+	// the public IP key of the attachment is the ID returned when the public IP is acquired
+	d := read("101", *ip.ID)
+	assert.Equal(t, "101", d.Id())
+	assert.Equal(t, *ip.ID, d.Get("public_ip_id"))
+	assert.Equal(t, *ip.Address, d.Get("address"))
+	assert.Equal(t, *ip.DNSName, d.Get("dns_name"))
+
+	// the configured public IP is kept when the key is not returned
+	d = read("102", "1234567")
+	assert.Equal(t, "1234567", d.Get("public_ip_id"))
+	assert.Equal(t, "203.0.113.11", d.Get("address"))
+
+	// once imported, the public IP is found by its address when the key is not returned
+	d = read("102", "")
+	assert.Equal(t, "7654321", d.Get("public_ip_id"))
+}
+
+func testAccCheckSkytapPublicIPAttachmentExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, err := getResource(s, name)
+		if err != nil {
+			return err
+		}
+
+		client := testAccProvider.Meta().(*SkytapClient).interfacesClient
+		ctx := context.TODO()
+		networkInterface, err := client.Get(ctx, rs.Primary.Attributes["environment_id"], rs.Primary.Attributes["vm_id"],
+			rs.Primary.Attributes["interface_id"])
+		if err != nil {
+			return fmt.Errorf("interface (%s) was not found: %v", rs.Primary.Attributes["interface_id"], err)
+		}
+		attachment := findPublicIPAttachment(networkInterface, func(attachment skytap.PublicIPAttachment) bool {
+			return attachment.ID != nil && fmt.Sprint(*attachment.ID) == rs.Primary.ID
+		})
+		if attachment == nil {
+			return fmt.Errorf("public IP attachment (%s) was not found", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccSkytapPublicIPAttachmentConfig(envTemplateID string, uniqueSuffixEnv int, templateID string, vmID string) string {
+	return testAccSkytapNetworkInterfaceConfig(envTemplateID, uniqueSuffixEnv, templateID, vmID,
+		"skytap_network.first", "10.0.4.10", "myhost") + `
+	resource "skytap_public_ip" "demo" {
+		region = "${skytap_environment.foo.region}"
+	}
+
+	resource "skytap_public_ip_attachment" "demo" {
+		environment_id = "${skytap_environment.foo.id}"
+		vm_id          = "${skytap_vm.bar.id}"
+		interface_id   = "${skytap_network_interface.baz.id}"
+		public_ip_id   = "${skytap_public_ip.demo.id}"
+	}`
+}
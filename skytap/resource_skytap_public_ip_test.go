@@ -0,0 +1,80 @@
+package skytap
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func TestAccSkytapPublicIP_Basic(t *testing.T) {
+	region := utils.GetEnv("SKYTAP_REGION", "US-West")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapPublicIPDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapPublicIPConfig(region),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapPublicIPExists("skytap_public_ip.foo"),
+					resource.TestCheckResourceAttr("skytap_public_ip.foo", "region", region),
+					resource.TestCheckResourceAttrSet("skytap_public_ip.foo", "address"),
+				),
+			},
+			{
+				ResourceName:      "skytap_public_ip.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckSkytapPublicIPExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, err := getResource(s, name)
+		if err != nil {
+			return err
+		}
+
+		_, err = getPublicIP(context.TODO(), testAccProvider.Meta(), rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("public IP (%s) was not found: %v", rs.Primary.ID, err)
+		}
+		return nil
+	}
+}
+
+func testAccCheckSkytapPublicIPDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "skytap_public_ip" {
+			continue
+		}
+
+		_, err := getPublicIP(context.TODO(), testAccProvider.Meta(), rs.Primary.ID)
+		if err != nil {
+			if utils.ResponseErrorIsNotFound(err) {
+				continue
+			}
+
+			return fmt.Errorf("error waiting for public IP (%s) to be released: %s", rs.Primary.ID, err)
+		}
+
+		return fmt.Errorf("public IP still exists: %s", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func testAccSkytapPublicIPConfig(region string) string {
+	return fmt.Sprintf(`
+	resource "skytap_public_ip" "foo" {
+		region = "%s"
+	}`, region)
+}
@@ -0,0 +1,193 @@
+package skytap
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/skytap/skytap-sdk-go/skytap"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func resourceSkytapPublishedService() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSkytapPublishedServiceCreate,
+		ReadContext:   resourceSkytapPublishedServiceRead,
+		DeleteContext: resourceSkytapPublishedServiceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateInterfaceChild,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"environment_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the environment containing the VM",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"vm_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the VM containing the network interface",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"interface_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the network interface the service is published on",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"internal_port": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The port that is exposed on the interface",
+				ValidateFunc: validation.IsPortNumber,
+			},
+
+			// computed attributes
+			"external_ip": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The IP address the service is reachable on from the public Internet",
+			},
+
+			"external_port": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The port the service is reachable on from the public Internet",
+			},
+		},
+	}
+}
+
+func resourceSkytapPublishedServiceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).publishedServicesClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	interfaceID := d.Get("interface_id").(string)
+
+	internalPort := skytap.CreatePublishedServiceRequest{
+		InternalPort: utils.Int(d.Get("internal_port").(int)),
+	}
+
+	if err := waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] creating published service")
+	log.Printf("[TRACE] creating published service: %v", spew.Sdump(internalPort))
+	publishedService, err := client.Create(ctx, environmentID, vmID, interfaceID, &internalPort)
+	if err != nil {
+		return diag.Errorf("error creating published service: %v", err)
+	}
+
+	if publishedService.ID == nil {
+		return diag.Errorf("published service ID is not set")
+	}
+	d.SetId(*publishedService.ID)
+
+	log.Printf("[INFO] created published service: %s", *publishedService.ID)
+	log.Printf("[TRACE] created published service: %v", spew.Sdump(publishedService))
+
+	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSkytapPublishedServiceRead(ctx, d, meta)
+}
+
+func resourceSkytapPublishedServiceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).publishedServicesClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	interfaceID := d.Get("interface_id").(string)
+	id := d.Id()
+
+	log.Printf("[INFO] retrieving published service: %s", id)
+	publishedService, err := client.Get(ctx, environmentID, vmID, interfaceID, id)
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] published service (%s) was not found - removing from state", id)
+			d.SetId("")
+			return nil
+		}
+
+		return diag.Errorf("error retrieving published service (%s): %v", id, err)
+	}
+
+	err = d.Set("environment_id", environmentID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("vm_id", vmID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("interface_id", interfaceID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("internal_port", publishedService.InternalPort)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("external_ip", publishedService.ExternalIP)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("external_port", publishedService.ExternalPort)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] published service retrieved: %s", id)
+	log.Printf("[TRACE] published service retrieved: %v", spew.Sdump(publishedService))
+
+	return nil
+}
+
+func resourceSkytapPublishedServiceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).publishedServicesClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	interfaceID := d.Get("interface_id").(string)
+	id := d.Id()
+
+	log.Printf("[INFO] destroying published service: %s", id)
+	err := client.Delete(ctx, environmentID, vmID, interfaceID, id)
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] published service (%s) was not found - assuming removed", id)
+			return nil
+		}
+
+		return diag.Errorf("error deleting published service (%s): %v", id, err)
+	}
+	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutDelete); err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] published service destroyed: %s", id)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package skytap
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccSkytapPublishedService_Basic(t *testing.T) {
+	templateID, vmID, newEnvTemplateID := setupEnvironment()
+	uniqueSuffixEnv := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapPublishedServiceConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, 22),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapPublishedServiceExists("skytap_published_service.ssh"),
+					resource.TestCheckResourceAttrPair("skytap_published_service.ssh", "interface_id", "skytap_network_interface.baz", "id"),
+					resource.TestCheckResourceAttr("skytap_published_service.ssh", "internal_port", "22"),
+					resource.TestCheckResourceAttrSet("skytap_published_service.ssh", "external_ip"),
+					resource.TestCheckResourceAttrSet("skytap_published_service.ssh", "external_port"),
+				),
+			},
+			{
+				Config: testAccSkytapPublishedServiceConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, 8080),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapPublishedServiceExists("skytap_published_service.ssh"),
+					resource.TestCheckResourceAttr("skytap_published_service.ssh", "internal_port", "8080"),
+				),
+			},
+			{
+				ResourceName:      "skytap_published_service.ssh",
+				ImportState:       true,
+				ImportStateIdFunc: testAccSkytapInterfaceChildImportStateIDFunc("skytap_published_service.ssh"),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckSkytapPublishedServiceExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, err := getResource(s, name)
+		if err != nil {
+			return err
+		}
+
+		client := testAccProvider.Meta().(*SkytapClient).publishedServicesClient
+		ctx := context.TODO()
+		_, err = client.Get(ctx, rs.Primary.Attributes["environment_id"], rs.Primary.Attributes["vm_id"],
+			rs.Primary.Attributes["interface_id"], rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("published service (%s) was not found: %v", rs.Primary.ID, err)
+		}
+		return nil
+	}
+}
+
+func testAccSkytapInterfaceChildImportStateIDFunc(name string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, err := getResource(s, name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s/%s/%s/%s", rs.Primary.Attributes["environment_id"], rs.Primary.Attributes["vm_id"],
+			rs.Primary.Attributes["interface_id"], rs.Primary.ID), nil
+	}
+}
+
+func testAccSkytapPublishedServiceConfig(envTemplateID string, uniqueSuffixEnv int, templateID string, vmID string,
+	internalPort int) string {
+	return testAccSkytapNetworkInterfaceConfig(envTemplateID, uniqueSuffixEnv, templateID, vmID,
+		"skytap_network.first", "10.0.4.10", "myhost") + fmt.Sprintf(`
+	resource "skytap_published_service" "ssh" {
+		environment_id = "${skytap_environment.foo.id}"
+		vm_id          = "${skytap_vm.bar.id}"
+		interface_id   = "${skytap_network_interface.baz.id}"
+		internal_port  = %d
+	}`, internalPort)
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/skytap/skytap-sdk-go/skytap"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/hashcode"
 	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
 )
 
@@ -22,6 +27,15 @@ func resourceSkytapVM() *schema.Resource {
 		ReadContext:   resourceSkytapVMRead,
 		UpdateContext: resourceSkytapVMUpdate,
 		DeleteContext: resourceSkytapVMDelete,
+		CustomizeDiff: customdiff.Sequence(
+			resourceSkytapVMTemplateVMNameDiff,
+			resourceSkytapVMHardwareDiff,
+			resourceSkytapVMPrimaryNetworkInterfaceDiff,
+			resourceSkytapVMPublishedServicesDiff,
+		),
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateEnvironmentChild,
+		},
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(20 * time.Minute),
@@ -29,244 +43,612 @@ func resourceSkytapVM() *schema.Resource {
 			Delete: schema.DefaultTimeout(20 * time.Minute),
 		},
 
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceSkytapVMResourceV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceSkytapVMStateUpgradeV0,
+				Version: 0,
+			},
+		},
+
+		Schema: resourceSkytapVMSchema(),
+	}
+}
+
+func resourceSkytapVMSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"environment_id": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			Description:  "ID of the environment you want to add the VM to",
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		"template_id": {
+			Type:             schema.TypeString,
+			Required:         true,
+			ForceNew:         true,
+			Description:      "ID of the template you want to create the VM from",
+			ValidateFunc:     validation.NoZeroValues,
+			DiffSuppressFunc: importedSuppress,
+		},
+
+		"vm_id": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Computed:         true,
+			ForceNew:         true,
+			Description:      "ID of the VM within the template that you want to create the VM from",
+			ValidateFunc:     validation.NoZeroValues,
+			DiffSuppressFunc: importedSuppress,
+			ExactlyOneOf:     []string{"vm_id", "template_vm_name"},
+		},
+
+		"template_vm_name": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Description:      "Name of the VM within the template that you want to create the VM from. It is resolved to the `vm_id` when planning",
+			ValidateFunc:     validation.NoZeroValues,
+			DiffSuppressFunc: importedSuppress,
+		},
+
+		"name": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "User-defined name of the VM",
+			ValidateFunc: validation.StringLenBetween(1, 100),
+		},
+
+		"cpus": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Number of CPUs allocated to this virtual machine",
+			ValidateFunc: validation.IntBetween(1, 12),
+		},
+
+		"max_cpus": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Maximum settable CPUs for the VM",
+		},
+
+		"cpus_per_socket": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Number of CPUs per socket. The number of CPUs must be a multiple of it",
+			ValidateFunc: validation.IntBetween(1, 12),
+		},
+
+		"ram": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Amount of RAM allocated to the VM",
+			ValidateFunc: validation.IntBetween(256, 131072),
+		},
+
+		"max_ram": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Maximum amount of RAM that can be allocated to the VM",
+		},
+
+		"os_disk_size": {
+			Type:         schema.TypeInt,
+			Computed:     true,
+			Optional:     true,
+			Description:  "The size of the OS disk. The disk size is in MiB; it will be converted to GiB in the Skytap UI. The maximum disk size is 2,096,128 MiB (1.999 TiB)",
+			ValidateFunc: validation.IntBetween(2048, 2096128),
+		},
+
+		"disk": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "Set of virtual disks within the VM",
+			Set:         vmDiskHash,
+			Elem:        vmDiskResource(),
+		},
+
+		"network_interface": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "List of virtualized network interface cards (also known as a network adapters). The position in the list is the index of the network adapter within the VM, so the first interface is the primary adapter",
+			ForceNew:    true,
+			Elem:        vmNetworkInterfaceResource(),
+		},
+		"service_ips": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Map of external IP addresses. The key is the name of a published service - as defined in the `published_service` block",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"service_ports": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Map of external IP addresses. The key is the name of a published service - as defined in the `published_service` block",
+			Elem:        &schema.Schema{Type: schema.TypeInt},
+		},
+		"user_data": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "VM user data, available from the metadata server and the Skytap API",
+		},
+
+		"nested_virtualization": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Whether the VM can run hypervisors, such as VMware ESXi or Hyper-V",
+		},
+
+		"time_sync_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Whether the VM clock is synchronized with the host clock",
+		},
+
+		"copy_paste_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Whether copying and pasting between the VM and the Skytap desktop client is enabled",
+		},
+
+		"vnc_keymap": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The keyboard layout used by the VM console, such as `en-us`",
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		"rtc_start_time": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The time the VM clock is set to when the VM starts, in the format `YYYY/MM/DD HH:MM:SS`. Time synchronization must be disabled",
+			ValidateFunc: validateRTCStartTime(),
+		},
+
+		"instance_type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The instance type of the VM",
+			ValidateFunc: validation.NoZeroValues,
+		},
+
+		"desktop_resizable": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Whether the VM display is resized to the size of the Skytap desktop client window",
+		},
+
+		"local_mouse_cursor": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Whether the local mouse cursor is used in the Skytap desktop client instead of the VM cursor",
+		},
+
+		"hardware_version": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The virtual hardware version of the VM. Raising it upgrades the VM, which is stopped during the upgrade. The hardware version cannot be lowered",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+
+		"max_hardware_version": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "The highest virtual hardware version the VM can be upgraded to",
+		},
+
+		"hardware_upgradable": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "Whether the virtual hardware of the VM can be upgraded",
+		},
+
+		"runstate": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Computed:         true,
+			Description:      "The desired runstate of the VM: `running`, `stopped`, `suspended` or `halted`. A `halted` VM is shut down through the guest OS and is reported as `stopped` once complete. If not set, the VM is started after creation and its runstate is left unmanaged",
+			ValidateFunc:     validateVMRunstate(),
+			DiffSuppressFunc: vmRunstateSuppress,
+		},
+
+		"label": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "Set of labels for the instance",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"category": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Label category that provides contextual meaning",
+					},
+					"value": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Label value used for reporting",
+					},
+					"id": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+// vmNetworkInterfaceResource is the schema of a network interface within the VM
+// vmDiskResource is the schema of a disk of the VM
+func vmDiskResource() *schema.Resource {
+	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
-			"environment_id": {
+			"name": {
 				Type:         schema.TypeString,
 				Required:     true,
-				ForceNew:     true,
-				Description:  "ID of the environment you want to add the VM to",
-				ValidateFunc: validation.NoZeroValues,
+				Description:  "A unique name for the disk",
+				ValidateFunc: validation.StringLenBetween(1, 33),
+			},
+			"size": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "The size of the disk specified in MiB. The minimum disk size is 2048 MiB; the maximum is 2,096,128 MiB (1.999 TiB)",
+				ValidateFunc: validation.IntBetween(2048, 2096128),
+			},
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of disk",
+			},
+			"controller": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The disk controller",
+			},
+			"lun": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The logical unit number (LUN) of the disk",
 			},
+		},
+	}
+}
 
-			"template_id": {
+func vmNetworkInterfaceResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"interface_type": {
 				Type:         schema.TypeString,
 				Required:     true,
 				ForceNew:     true,
-				Description:  "ID of the template you want to create the VM from",
-				ValidateFunc: validation.NoZeroValues,
+				Description:  "Type of network that this network adapter is attached to",
+				ValidateFunc: validateNICType(),
 			},
-
-			"vm_id": {
+			"network_id": {
 				Type:         schema.TypeString,
 				Required:     true,
 				ForceNew:     true,
-				Description:  "ID of the VM within the template that you want to create the VM from",
+				Description:  "ID of the network that this network adapter is attached to",
 				ValidateFunc: validation.NoZeroValues,
 			},
-
-			"name": {
+			"ip": {
 				Type:         schema.TypeString,
-				Optional:     true,
-				Computed:     true,
-				Description:  "User-defined name of the VM",
-				ValidateFunc: validation.StringLenBetween(1, 100),
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The IP address (for example, 10.1.0.37). Skytap will not assign the same IP address to multiple interfaces on the same network",
+				ValidateFunc: validation.IsIPAddress,
 			},
-
-			"cpus": {
-				Type:         schema.TypeInt,
-				Optional:     true,
-				Computed:     true,
-				Description:  "Number of CPUs allocated to this virtual machine",
-				ValidateFunc: validation.IntBetween(1, 12),
+			"hostname": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "Hostname of the VM",
+				ValidateFunc: validateHostname(),
 			},
-
-			"max_cpus": {
-				Type:        schema.TypeInt,
-				Computed:    true,
-				Description: "Maximum settable CPUs for the VM",
+			"primary": {
+				Type:             schema.TypeBool,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: vmNetworkInterfacePrimarySuppress,
+				Description:      "Whether this is the primary network adapter of the VM. Only the first network interface can be the primary, and setting it to `false` has no effect",
 			},
-
-			"ram": {
-				Type:         schema.TypeInt,
-				Optional:     true,
-				Computed:     true,
-				Description:  "Amount of RAM allocated to the VM",
-				ValidateFunc: validation.IntBetween(256, 131072),
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
 			},
-
-			"max_ram": {
-				Type:        schema.TypeInt,
+			"secondary_ips": {
+				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "Maximum amount of RAM that can be allocated to the VM",
-			},
-
-			"os_disk_size": {
-				Type:         schema.TypeInt,
-				Computed:     true,
-				Optional:     true,
-				Description:  "The size of the OS disk. The disk size is in MiB; it will be converted to GiB in the Skytap UI. The maximum disk size is 2,096,128 MiB (1.999 TiB)",
-				ValidateFunc: validation.IntBetween(2048, 2096128),
+				Description: "The secondary IP addresses of the network adapter",
+				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
 
-			"disk": {
+			"published_service": {
 				Type:        schema.TypeSet,
 				Optional:    true,
-				Description: "Set of virtual disks within the VM",
+				Description: "A binding of a port on a network interface to an IP and port that is routable and accessible from the public Internet. This mechanism is used to selectively expose ports on the guest to the public Internet",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"name": {
 							Type:         schema.TypeString,
 							Required:     true,
-							Description:  "A unique name for the disk",
-							ValidateFunc: validation.StringLenBetween(1, 33),
+							Description:  "A unique name for the published service",
+							ValidateFunc: validation.NoZeroValues,
 						},
-						"size": {
+						"internal_port": {
 							Type:         schema.TypeInt,
 							Required:     true,
-							Description:  "The size of the disk specified in MiB. The minimum disk size is 2048 MiB; the maximum is 2,096,128 MiB (1.999 TiB)",
-							ValidateFunc: validation.IntBetween(2048, 2096128),
+							Description:  "The port that is exposed on the interface",
+							ValidateFunc: validation.NoZeroValues,
 						},
 						"id": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
-						"type": {
+						"external_ip": {
 							Type:        schema.TypeString,
 							Computed:    true,
-							Description: "The type of disk",
+							Description: "The published service's external IP",
 						},
-						"controller": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: "The disk controller",
-						},
-						"lun": {
-							Type:        schema.TypeString,
+						"external_port": {
+							Type:        schema.TypeInt,
 							Computed:    true,
-							Description: "The logical unit number (LUN) of the disk",
+							Description: "The published service's external port",
 						},
 					},
 				},
 			},
+		},
+	}
+}
 
-			"network_interface": {
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Computed:    true,
-				Description: "Set of virtualized network interface cards (also known as a network adapters)",
-				ForceNew:    true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"interface_type": {
-							Type:         schema.TypeString,
-							Required:     true,
-							ForceNew:     true,
-							Description:  "Type of network that this network adapter is attached to",
-							ValidateFunc: validateNICType(),
-						},
-						"network_id": {
-							Type:         schema.TypeString,
-							Required:     true,
-							ForceNew:     true,
-							Description:  "ID of the network that this network adapter is attached to",
-							ValidateFunc: validation.NoZeroValues,
-						},
-						"ip": {
-							Type:         schema.TypeString,
-							Required:     true,
-							ForceNew:     true,
-							Description:  "The IP address (for example, 10.1.0.37). Skytap will not assign the same IP address to multiple interfaces on the same network",
-							ValidateFunc: validation.IsIPAddress,
-						},
-						"hostname": {
-							Type:        schema.TypeString,
-							Required:    true,
-							ForceNew:    true,
-							Description: "Hostname of the VM",
-							ValidateFunc: validation.All(
-								validation.StringLenBetween(1, 32),
-								validation.StringMatch(regexp.MustCompile(`^(?:[a-z0-9][a-z0-9-]*)?[a-z0-9]$`), "Valid characters are lowercase letters, numbers, and hyphens. Cannot begin or end with hyphens"),
-								validation.StringNotInSlice([]string{"gw"}, true),
-							),
-						},
-						"id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
+// resourceSkytapVMPrimaryNetworkInterfaceDiff checks only the first network interface is set as the primary,
+// as the primary is the first network adapter of the VM
+func resourceSkytapVMPrimaryNetworkInterfaceDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	for idx, v := range d.Get("network_interface").([]interface{}) {
+		networkInterface, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if primary, ok := networkInterface["primary"].(bool); ok && primary && idx > 0 {
+			return fmt.Errorf("network interface (%d) is set as the primary, but only the first network interface "+
+				"can be the primary", idx)
+		}
+	}
+	return nil
+}
 
-						"published_service": {
-							Type:        schema.TypeSet,
-							Optional:    true,
-							ForceNew:    true,
-							Description: "A binding of a port on a network interface to an IP and port that is routable and accessible from the public Internet. This mechanism is used to selectively expose ports on the guest to the public Internet",
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"name": {
-										Type:         schema.TypeString,
-										Required:     true,
-										ForceNew:     true,
-										Description:  "A unique name for the published service",
-										ValidateFunc: validation.NoZeroValues,
-									},
-									"internal_port": {
-										Type:         schema.TypeInt,
-										Required:     true,
-										ForceNew:     true,
-										Description:  "The port that is exposed on the interface",
-										ValidateFunc: validation.NoZeroValues,
-									},
-									"id": {
-										Type:     schema.TypeString,
-										Computed: true,
-									},
-									"external_ip": {
-										Type:        schema.TypeString,
-										Computed:    true,
-										Description: "The published service's external IP",
-									},
-									"external_port": {
-										Type:        schema.TypeInt,
-										Computed:    true,
-										Description: "The published service's external port",
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-			"service_ips": {
-				Type:        schema.TypeMap,
-				Computed:    true,
-				Description: "Map of external IP addresses. The key is the name of a published service - as defined in the `published_service` block",
-				Elem:        &schema.Schema{Type: schema.TypeString},
-			},
-			"service_ports": {
-				Type:        schema.TypeMap,
-				Computed:    true,
-				Description: "Map of external IP addresses. The key is the name of a published service - as defined in the `published_service` block",
-				Elem:        &schema.Schema{Type: schema.TypeInt},
-			},
-			"user_data": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "VM user data, available from the metadata server and the Skytap API",
-			},
+// resourceSkytapVMPublishedServicesDiff marks the service maps as changing when the published services of an existing
+// VM change, as the external IPs and ports are only known once the services are created
+func resourceSkytapVMPublishedServicesDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("network_interface") {
+		return nil
+	}
+	if err := d.SetNewComputed("service_ports"); err != nil {
+		return err
+	}
+	return d.SetNewComputed("service_ips")
+}
 
-			"label": {
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Description: "Set of labels for the instance",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"category": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "Label category that provides contextual meaning",
-						},
-						"value": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "Label value used for reporting",
-						},
-						"id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-					},
-				},
-			},
-		},
+// vmNetworkInterfacePrimarySuppress ignores `primary` unless it is set to true. The first network interface is
+// always the primary, so it does not need to be set.
+func vmNetworkInterfacePrimarySuppress(_, _, new string, _ *schema.ResourceData) bool {
+	return new != "true"
+}
+
+// resourceSkytapVMTemplateVMNameDiff resolves the `template_vm_name` to the `vm_id` of the template VM. It is only resolved
+// when the VM is created or the template VM changes, so re-saving the template does not replace existing VMs.
+func resourceSkytapVMTemplateVMNameDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	name := d.Get("template_vm_name").(string)
+	if name == "" || (d.Id() != "" && !d.HasChange("template_id") && !d.HasChange("template_vm_name")) {
+		return nil
+	}
+	if !d.NewValueKnown("template_id") {
+		return d.SetNewComputed("vm_id")
+	}
+
+	templateVMID, err := getTemplateVMID(ctx, meta, d.Get("template_id").(string), name)
+	if err != nil {
+		return err
+	}
+	if templateVMID == d.Get("vm_id").(string) {
+		return nil
+	}
+	return d.SetNew("vm_id", templateVMID)
+}
+
+// resourceSkytapVMHardwareDiff rejects hardware changes which cannot be applied, before the VM is created or updated.
+// The limits come from the template VM when creating, and from the VM itself when updating.
+func resourceSkytapVMHardwareDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && !d.HasChange("cpus") && !d.HasChange("cpus_per_socket") && !d.HasChange("ram") &&
+		!d.HasChange("os_disk_size") && !d.HasChange("disk") && !d.HasChange("hardware_version") {
+		return nil
+	}
+	for _, key := range []string{"template_id", "vm_id", "environment_id", "cpus", "cpus_per_socket", "ram", "os_disk_size",
+		"hardware_version"} {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	var vm *skytap.VM
+	var err error
+	if d.Id() == "" {
+		templateID := d.Get("template_id").(string)
+		templateVMID := d.Get("vm_id").(string)
+		vm, err = getTemplateVM(ctx, meta, templateID, templateVMID)
+		if err != nil {
+			return err
+		}
+	} else {
+		client := meta.(*SkytapClient).vmsClient
+		environmentID := d.Get("environment_id").(string)
+		vm, err = client.Get(ctx, environmentID, d.Id())
+		if err != nil {
+			if utils.ResponseErrorIsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("error retrieving VM (%s): %v", d.Id(), err)
+		}
+	}
+
+	if v, ok := d.GetOk("hardware_version"); ok {
+		if err = checkVMHardwareVersion(vm, v.(int)); err != nil {
+			return err
+		}
+	}
+
+	hardware := vm.Hardware
+	if hardware == nil {
+		return nil
+	}
+
+	var cpus, cpusPerSocket, ram, osDiskSize int
+	if v, ok := d.GetOk("cpus"); ok {
+		cpus = v.(int)
+	}
+	if v, ok := d.GetOk("cpus_per_socket"); ok {
+		cpusPerSocket = v.(int)
+	}
+	if v, ok := d.GetOk("ram"); ok {
+		ram = v.(int)
+	}
+	if v, ok := d.GetOk("os_disk_size"); ok {
+		osDiskSize = v.(int)
+	}
+	if err := checkVMHardware(hardware, cpus, cpusPerSocket, ram, osDiskSize); err != nil {
+		return err
+	}
+
+	if d.Id() != "" && d.HasChange("disk") {
+		oldDisks, newDisks := d.GetChange("disk")
+		matched := matchDisks(oldDisks.(*schema.Set), newDisks.(*schema.Set))
+		for _, disk := range newDisks.(*schema.Set).List() {
+			diskMap := disk.(map[string]interface{})
+			name := diskMap["name"].(string)
+			if oldDisk, ok := matched[name]; ok {
+				if err := checkDiskNotShrunk(oldDisk["size"].(int), diskMap["size"].(int), name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkVMHardware checks the requested CPUs, CPUs per socket, RAM and OS disk size fit the limits of the hardware.
+// Zero values are not requested, so the current value of the hardware is kept.
+func checkVMHardware(hardware *skytap.Hardware, cpus int, cpusPerSocket int, ram int, osDiskSize int) error {
+	if cpus > 0 && hardware.MaxCPUs != nil && cpus > *hardware.MaxCPUs {
+		return outOfRangeError("cpus", cpus, *hardware.MaxCPUs)
+	}
+	if ram > 0 && hardware.MaxRAM != nil && ram > *hardware.MaxRAM {
+		return outOfRangeError("ram", ram, *hardware.MaxRAM)
+	}
+
+	if cpus == 0 && hardware.CPUs != nil {
+		cpus = *hardware.CPUs
+	}
+	if ram == 0 && hardware.RAM != nil {
+		ram = *hardware.RAM
+	}
+	if cpus > 0 && ram > 0 && cpus > mbToGb(ram) {
+		return cpusExceedsRamError(cpus, mbToGb(ram))
+	}
+	if cpusPerSocket == 0 && hardware.CpusPerSocket != nil {
+		cpusPerSocket = *hardware.CpusPerSocket
+	}
+	if cpusPerSocket > 1 && cpus%cpusPerSocket != 0 {
+		return cpusPerSocketError(cpus, cpusPerSocket)
+	}
+
+	if osDiskSize > 0 && len(hardware.Disks) > 0 && hardware.Disks[0].Size != nil {
+		if err := checkDiskNotShrunk(*hardware.Disks[0].Size, osDiskSize, "OS"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVMHardwareVersion checks the VM can be moved to the requested hardware version. The hardware version can only
+// be raised, up to the maximum version supported by the VM.
+func checkVMHardwareVersion(vm *skytap.VM, version int) error {
+	if vm.HardwareVersion == nil || version == *vm.HardwareVersion {
+		return nil
+	}
+	if version < *vm.HardwareVersion {
+		return hardwareVersionDowngradeError(version, *vm.HardwareVersion)
+	}
+	if vm.MaxHardwareVersion != nil && version > *vm.MaxHardwareVersion {
+		return outOfRangeError("hardware_version", version, *vm.MaxHardwareVersion)
+	}
+	if vm.Hardware != nil && vm.Hardware.Upgradable != nil && !*vm.Hardware.Upgradable {
+		return fmt.Errorf("the 'hardware_version' argument has been assigned (%d) but the hardware of this VM "+
+			"cannot be upgraded from version (%d)", version, *vm.HardwareVersion)
+	}
+	return nil
+}
+
+// getTemplateVM retrieves the VM with the given ID within the template
+func getTemplateVM(ctx context.Context, meta interface{}, templateID string, templateVMID string) (*skytap.VM, error) {
+	client := meta.(*SkytapClient).templatesClient
+
+	template, err := client.Get(ctx, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving template (%s): %v", templateID, err)
+	}
+	for _, vm := range template.VMs {
+		if vm.ID != nil && *vm.ID == templateVMID {
+			return &vm, nil
+		}
+	}
+	return nil, fmt.Errorf("template (%s) has no VM with ID (%s)", templateID, templateVMID)
+}
+
+// getTemplateVMID finds the ID of the single VM with the given name within the template
+func getTemplateVMID(ctx context.Context, meta interface{}, templateID string, name string) (string, error) {
+	client := meta.(*SkytapClient).templatesClient
+
+	template, err := client.Get(ctx, templateID)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving template (%s): %v", templateID, err)
+	}
+
+	var ids []string
+	for _, vm := range template.VMs {
+		if vm.Name != nil && *vm.Name == name && vm.ID != nil {
+			ids = append(ids, *vm.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("template (%s) has no VM named %q", templateID, name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("template (%s) has %d VMs named %q (%s), set `vm_id` to choose one of them",
+			templateID, len(ids), name, strings.Join(ids, ", "))
 	}
 }
 
@@ -305,6 +687,26 @@ func resourceSkytapVMCreate(ctx context.Context, d *schema.ResourceData, meta in
 		}
 	}
 
+	// The CPUs must remain a multiple of the CPUs per socket, so the settings are applied before the CPUs
+	// when the requested CPUs per socket fits the CPUs of the template VM
+	configured := func(key string) bool {
+		_, ok := d.GetOkExists(key)
+		return ok
+	}
+	settingsFirst := false
+	if v, ok := d.GetOk("cpus_per_socket"); ok {
+		vm, err := client.Get(ctx, environmentID, id)
+		if err != nil {
+			return diag.Errorf("error retrieving VM (%s): %v", id, err)
+		}
+		settingsFirst = vm.Hardware != nil && vm.Hardware.CPUs != nil && *vm.Hardware.CPUs%v.(int) == 0
+	}
+	if settingsFirst {
+		if err = updateVMSettings(ctx, d, meta, configured, schema.TimeoutCreate); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	vmDisks, err := addVMHardware(ctx, d, meta, environmentID, id)
 	if err != nil {
 		return diag.FromErr(err)
@@ -315,6 +717,12 @@ func resourceSkytapVMCreate(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.FromErr(err)
 	}
 
+	if !settingsFirst {
+		if err = updateVMSettings(ctx, d, meta, configured, schema.TimeoutCreate); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if userData, ok := d.GetOk("user_data"); ok {
 		if err := client.UpdateUserData(ctx, environmentID, id, utils.String(userData.(string))); err != nil {
 			return diag.FromErr(err)
@@ -330,23 +738,13 @@ func resourceSkytapVMCreate(ctx context.Context, d *schema.ResourceData, meta in
 		}
 	}
 
-	if err = forceRunning(ctx, meta, environmentID, id); err != nil {
-		return diag.FromErr(err)
-	}
-
-	stateConfUpdate := &resource.StateChangeConf{
-		Pending:    getVMPendingUpdateRunstates(true),
-		Target:     getVMTargetUpdateRunstates(true),
-		Refresh:    vmRunstateRefreshFunc(ctx, d, meta),
-		Timeout:    d.Timeout(schema.TimeoutCreate),
-		MinTimeout: minTimeout * time.Second,
-		Delay:      delay * time.Second,
+	// The VM is stopped after creation. Start it unless another runstate is requested.
+	runstate := skytap.VMRunstateRunning
+	if v, ok := d.GetOk("runstate"); ok {
+		runstate = skytap.VMRunstate(v.(string))
 	}
-
-	log.Printf("[INFO] Waiting for VM (%s) to complete", d.Id())
-	_, err = stateConfUpdate.WaitForStateContext(ctx)
-	if err != nil {
-		return diag.Errorf("error waiting for VM (%s) to complete: %s", d.Id(), err)
+	if err = changeVMRunstate(ctx, d, meta, runstate, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
 	}
 
 	return resourceSkytapVMRead(ctx, d, meta)
@@ -396,6 +794,60 @@ func resourceSkytapVMRead(ctx context.Context, d *schema.ResourceData, meta inte
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	if vm.Runstate != nil {
+		err = d.Set("runstate", string(*vm.Runstate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	err = d.Set("cpus_per_socket", vm.Hardware.CpusPerSocket)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("nested_virtualization", vm.Hardware.NestedVirtualization)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("time_sync_enabled", vm.Hardware.TimeSyncEnabled)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("copy_paste_enabled", vm.Hardware.CopyPasteEnabled)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("vnc_keymap", vm.Hardware.VncKeymap)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("rtc_start_time", vm.Hardware.RTCStartTime)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("instance_type", vm.Hardware.InstanceType)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("desktop_resizable", vm.DesktopResizable)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("local_mouse_cursor", vm.LocalMouseCursor)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("hardware_version", vm.HardwareVersion)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("max_hardware_version", vm.MaxHardwareVersion)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("hardware_upgradable", vm.Hardware.Upgradable)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	userData, err := client.GetUserData(ctx, environmentID, id)
 	if err != nil {
@@ -411,13 +863,19 @@ func resourceSkytapVMRead(ctx context.Context, d *schema.ResourceData, meta inte
 	}
 
 	if len(vm.Interfaces) > 0 {
+		// The template is not returned by the API, so it is only empty after an import. Every network interface and
+		// published service is read then, otherwise the ones added outside of this resource, such as by a
+		// skytap_network_interface or a skytap_published_service, are left out.
+		imported := d.Get("template_id").(string) == ""
+		networkInterfaces := d.Get("network_interface").([]interface{})
 		// add the names
-		networkInterfaceSet := d.Get("network_interface").(*schema.Set)
-		for _, networkInterface := range networkInterfaceSet.List() {
+		for _, networkInterface := range networkInterfaces {
 			networkInterfaceMap := networkInterface.(map[string]interface{})
 			vmInterface, err := getVMNetworkInterface(networkInterfaceMap["id"].(string), vm)
 			if err != nil {
-				return diag.FromErr(err)
+				// the interface may have been removed outside of this resource, such as by a skytap_network_interface
+				log.Printf("[DEBUG] %v", err)
+				continue
 			}
 			if _, ok := networkInterfaceMap["published_service"]; ok {
 				publishedServiceSet := networkInterfaceMap["published_service"].(*schema.Set)
@@ -430,9 +888,15 @@ func resourceSkytapVMRead(ctx context.Context, d *schema.ResourceData, meta inte
 						}
 					}
 				}
+				if !imported {
+					vmInterface.Services = trackedPublishedServices(vmInterface.Services, publishedServiceSet)
+				}
 			}
 		}
-		networkSetFlattened := flattenNetworkInterfaces(vm.Interfaces)
+		networkSetFlattened := flattenVMNetworkInterfaces(vm.Interfaces)
+		if len(networkInterfaces) > 0 && !imported {
+			networkSetFlattened = trackedNetworkInterfaces(networkSetFlattened, networkInterfaces)
+		}
 
 		if err := d.Set("network_interface", networkSetFlattened); err != nil {
 			log.Printf("[ERROR] error flattening network interfaces: %v", err)
@@ -472,7 +936,13 @@ func resourceSkytapVMRead(ctx context.Context, d *schema.ResourceData, meta inte
 				}
 			}
 		}
-		diskSetFlattened := flattenDisks(vm.Hardware.Disks)
+		disks := vm.Hardware.Disks
+		// The template is not returned by the API, so it is only empty after an import. Every disk is read then,
+		// otherwise the disks added outside of this resource, such as by a skytap_vm_disk, are left out.
+		if diskSet.Len() > 0 || d.Get("template_id").(string) != "" {
+			disks = excludeDisks(disks, untrackedVMDisks(vm, diskSet))
+		}
+		diskSetFlattened := flattenDisks(disks)
 
 		if err := d.Set("disk", diskSetFlattened); err != nil {
 			log.Printf("[ERROR] error flattening disks: %v", err)
@@ -491,6 +961,17 @@ func resourceSkytapVMUpdate(ctx context.Context, d *schema.ResourceData, meta in
 	environmentID := d.Get("environment_id").(string)
 	id := d.Id()
 
+	// The CPUs must remain a multiple of the CPUs per socket, so the settings are updated before the CPUs
+	// when the new CPUs per socket fits the current CPUs
+	oldCPUs, _ := d.GetChange("cpus")
+	cpusPerSocket := d.Get("cpus_per_socket").(int)
+	settingsFirst := cpusPerSocket > 0 && oldCPUs.(int)%cpusPerSocket == 0
+	if settingsFirst {
+		if err := updateVMSettings(ctx, d, meta, d.HasChange, schema.TimeoutUpdate); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	opts := skytap.UpdateVMRequest{}
 
 	if v, ok := d.GetOk("name"); ok && d.HasChange("name") {
@@ -507,24 +988,32 @@ func resourceSkytapVMUpdate(ctx context.Context, d *schema.ResourceData, meta in
 	if d.HasChange("disk") || d.HasChange("name") || d.HasChange("ram") ||
 		d.HasChange("cpus") || d.HasChange("os_disk_size") {
 
-		log.Printf("[INFO] VM update: %s", id)
-		log.Printf("[TRACE] VM update options: %v", spew.Sdump(opts))
-		vm, err := client.Update(ctx, environmentID, id, &opts)
+		oldDisks, _ := d.GetChange("disk")
+		vm, untracked, err := updateVMKeepingUntrackedDisks(ctx, meta, environmentID, id, &opts, oldDisks.(*schema.Set))
 		if err != nil {
-			return diag.Errorf("error updating vm (%s): %v", id, err)
+			return diag.FromErr(err)
 		}
 
-		log.Printf("[INFO] updated VM: %s", id)
-		log.Printf("[TRACE] updated VM: %v", spew.Sdump(vm))
-
 		// Have to do this here in order to capture `name`
-		vmDisks = flattenDisks(vm.Hardware.Disks)
+		vmDisks = flattenDisks(excludeDisks(vm.Hardware.Disks, untracked))
 
 		if err := d.Set("disk", vmDisks); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
+	if !settingsFirst {
+		if err = updateVMSettings(ctx, d, meta, d.HasChange, schema.TimeoutUpdate); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("network_interface") {
+		if err = updatePublishedServices(ctx, d, meta, environmentID, id); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if d.HasChange("user_data") {
 		if userData, ok := d.GetOk("user_data"); ok {
 			if err := client.UpdateUserData(ctx, environmentID, id, utils.String(userData.(string))); err != nil {
@@ -552,19 +1041,12 @@ func resourceSkytapVMUpdate(ctx context.Context, d *schema.ResourceData, meta in
 		}
 	}
 
-	stateConf := &resource.StateChangeConf{
-		Pending:    getVMPendingUpdateRunstates(false),
-		Target:     getVMTargetUpdateRunstates(false),
-		Refresh:    vmRunstateRefreshFunc(ctx, d, meta),
-		Timeout:    d.Timeout(schema.TimeoutUpdate),
-		MinTimeout: minTimeout * time.Second,
-		Delay:      delay * time.Second,
-	}
-
-	log.Printf("[INFO] Waiting for VM (%s) to complete", d.Id())
-	_, err = stateConf.WaitForStateContext(ctx)
-	if err != nil {
-		return diag.Errorf("error waiting for VM (%s) to complete: %s", d.Id(), err)
+	if v, ok := d.GetOk("runstate"); ok && d.HasChange("runstate") {
+		if err = changeVMRunstate(ctx, d, meta, skytap.VMRunstate(v.(string)), schema.TimeoutUpdate); err != nil {
+			return diag.FromErr(err)
+		}
+	} else if err = waitForVMRunstate(ctx, d, meta, "", schema.TimeoutUpdate); err != nil {
+		return diag.FromErr(err)
 	}
 
 	return resourceSkytapVMRead(ctx, d, meta)
@@ -630,10 +1112,11 @@ func addNetworkAdapters(ctx context.Context, d *schema.ResourceData, meta interf
 			log.Printf("[INFO] deleted network interface: %s", *iface.ID)
 		}
 	}
-	networkInterfaceSet := d.Get("network_interface").(*schema.Set)
-	vmNetworkInterfaces := make([]skytap.Interface, networkInterfaceSet.Len())
-	log.Printf("[INFO] creating %d network interfaces", networkInterfaceSet.Len())
-	for idx, networkInterface := range networkInterfaceSet.List() {
+	// the interfaces are created in order, so their position in the list is their adapter index
+	networkInterfaces := d.Get("network_interface").([]interface{})
+	vmNetworkInterfaces := make([]skytap.Interface, len(networkInterfaces))
+	log.Printf("[INFO] creating %d network interfaces", len(networkInterfaces))
+	for idx, networkInterface := range networkInterfaces {
 		networkInterfaceMap := networkInterface.(map[string]interface{})
 		nicType := skytap.CreateInterfaceRequest{
 			NICType: utils.NICType(skytap.NICType(networkInterfaceMap["interface_type"].(string))),
@@ -700,7 +1183,7 @@ func addNetworkAdapters(ctx context.Context, d *schema.ResourceData, meta interf
 		}
 	}
 	// Have to do this here in order to capture `published_service` name
-	return flattenNetworkInterfaces(vmNetworkInterfaces), nil
+	return flattenVMNetworkInterfaces(vmNetworkInterfaces), nil
 }
 
 // create the public service for a specific interface
@@ -733,6 +1216,73 @@ func addPublishedServices(ctx context.Context, meta interface{}, environmentID s
 	return nil
 }
 
+// updatePublishedServices creates and deletes the published services of each network interface, matched by their
+// internal port. Only the name of the others can change, which needs no request as Skytap does not store it.
+func updatePublishedServices(ctx context.Context, d *schema.ResourceData, meta interface{}, environmentID string, vmID string) error {
+	client := meta.(*SkytapClient).publishedServicesClient
+
+	old, new := d.GetChange("network_interface")
+	oldNetworkInterfaces := old.([]interface{})
+	for idx, v := range new.([]interface{}) {
+		if idx >= len(oldNetworkInterfaces) {
+			break
+		}
+		oldNetworkInterface := oldNetworkInterfaces[idx].(map[string]interface{})
+		nicID := oldNetworkInterface["id"].(string)
+		oldServices := publishedServicesByPort(oldNetworkInterface["published_service"].(*schema.Set))
+		newServices := publishedServicesByPort(v.(map[string]interface{})["published_service"].(*schema.Set))
+
+		for _, port := range sortedPorts(oldServices) {
+			if _, ok := newServices[port]; ok {
+				continue
+			}
+			id := oldServices[port]["id"].(string)
+			log.Printf("[INFO] destroying published service: %s", id)
+			if err := client.Delete(ctx, environmentID, vmID, nicID, id); err != nil && !utils.ResponseErrorIsNotFound(err) {
+				return fmt.Errorf("error deleting published service (%s): %v", id, err)
+			}
+			log.Printf("[INFO] published service destroyed: %s", id)
+		}
+		for _, port := range sortedPorts(newServices) {
+			if _, ok := oldServices[port]; ok {
+				continue
+			}
+			internalPort := skytap.CreatePublishedServiceRequest{
+				InternalPort: utils.Int(port),
+			}
+			log.Printf("[INFO] creating published service")
+			log.Printf("[TRACE] creating published service: %v", spew.Sdump(internalPort))
+			publishedService, err := client.Create(ctx, environmentID, vmID, nicID, &internalPort)
+			if err != nil {
+				return fmt.Errorf("error creating published service: %v", err)
+			}
+			log.Printf("[INFO] created published service: %s", *publishedService.ID)
+			log.Printf("[TRACE] created published service: %v", spew.Sdump(publishedService))
+		}
+	}
+	return waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutUpdate)
+}
+
+// publishedServicesByPort indexes the published services by their internal port
+func publishedServicesByPort(publishedServices *schema.Set) map[int]map[string]interface{} {
+	result := make(map[int]map[string]interface{})
+	for _, v := range publishedServices.List() {
+		publishedService := v.(map[string]interface{})
+		result[publishedService["internal_port"].(int)] = publishedService
+	}
+	return result
+}
+
+// sortedPorts returns the ports of the published services in order, so that they are applied predictably
+func sortedPorts(publishedServices map[int]map[string]interface{}) []int {
+	ports := make([]int, 0, len(publishedServices))
+	for port := range publishedServices {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
 func addVMHardware(ctx context.Context, d *schema.ResourceData, meta interface{}, environmentID string, vmID string) (interface{}, error) {
 	client := meta.(*SkytapClient).vmsClient
 
@@ -829,12 +1379,16 @@ func updateHardware(d *schema.ResourceData) (*skytap.UpdateHardware, error) {
 		diskSet := newDisks.(*schema.Set)
 		diskIDs := make([]skytap.DiskIdentification, 0)
 		disksNew := make([]int, 0)
+		matched := matchDisks(oldDisks.(*schema.Set), diskSet)
 		// adds and initialises disk identification struct
 		for _, disk := range diskSet.List() {
 			diskMap := disk.(map[string]interface{})
 			name := diskMap["name"].(string)
 			sizeNew := diskMap["size"].(int)
-			id, sizeOld := retrieveIDsFromOldState(oldDisks.(*schema.Set), name)
+			id, sizeOld := "", 0
+			if oldDisk, ok := matched[name]; ok {
+				id, sizeOld = oldDisk["id"].(string), oldDisk["size"].(int)
+			}
 			if id == "" { // new
 				disksNew = append(disksNew, sizeNew)
 			} else {
@@ -912,35 +1466,204 @@ func cpusExceedsRamError(cpus, ram int) error {
 	return fmt.Errorf("the 'cpus' argument has been assigned (%d) which is more than the maximum allowed (%d), the number of GB of RAM", cpus, ram)
 }
 
-func retrieveIDsFromOldState(d *schema.Set, name string) (string, int) {
-	for _, disk := range d.List() {
-		diskMap := disk.(map[string]interface{})
-		if diskMap["name"] == name {
-			return diskMap["id"].(string), diskMap["size"].(int)
+func hardwareVersionDowngradeError(version, current int) error {
+	return fmt.Errorf("the 'hardware_version' argument has been assigned (%d) which is less than the current "+
+		"hardware version (%d). The hardware version of a VM cannot be lowered", version, current)
+}
+
+func cpusPerSocketError(cpus, cpusPerSocket int) error {
+	return fmt.Errorf("the 'cpus' argument has been assigned (%d) which is not a multiple of the CPUs per socket (%d)", cpus, cpusPerSocket)
+}
+
+// vmDiskMutexKV serializes the disk changes made to each VM. The SDK removes the disks which are not identified
+// in an update, so concurrent changes could remove each other's disks.
+var vmDiskMutexKV = utils.NewMutexKV()
+
+// updateVMKeepingUntrackedDisks updates the VM, identifying the disks which are not in the tracked set so the SDK
+// does not remove them. It returns the updated VM and the untracked disks.
+func updateVMKeepingUntrackedDisks(ctx context.Context, meta interface{}, environmentID string, id string,
+	opts *skytap.UpdateVMRequest, tracked *schema.Set) (*skytap.VM, []skytap.Disk, error) {
+	client := meta.(*SkytapClient).vmsClient
+
+	vmDiskMutexKV.Lock(id)
+	defer vmDiskMutexKV.Unlock(id)
+
+	vm, err := client.Get(ctx, environmentID, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving VM (%s): %v", id, err)
+	}
+	untracked := untrackedVMDisks(vm, tracked)
+	for _, disk := range untracked {
+		opts.Hardware.UpdateDisks.DiskIdentification = append(opts.Hardware.UpdateDisks.DiskIdentification,
+			skytap.DiskIdentification{ID: disk.ID, Size: disk.Size})
+	}
+
+	log.Printf("[INFO] VM update: %s", id)
+	log.Printf("[TRACE] VM update options: %v", spew.Sdump(opts))
+	vm, err = client.Update(ctx, environmentID, id, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error updating vm (%s): %v", id, err)
+	}
+
+	log.Printf("[INFO] updated VM: %s", id)
+	log.Printf("[TRACE] updated VM: %v", spew.Sdump(vm))
+	return vm, untracked, nil
+}
+
+// untrackedVMDisks returns the disks of the VM, other than the OS disk, which are not in the tracked set,
+// such as the disks managed by a skytap_vm_disk
+func untrackedVMDisks(vm *skytap.VM, tracked *schema.Set) []skytap.Disk {
+	ids := make(map[string]bool)
+	for _, disk := range tracked.List() {
+		ids[disk.(map[string]interface{})["id"].(string)] = true
+	}
+	untracked := make([]skytap.Disk, 0)
+	for idx, disk := range vm.Hardware.Disks {
+		if idx > 0 && disk.ID != nil && !ids[*disk.ID] {
+			untracked = append(untracked, disk)
 		}
 	}
-	return "", 0
+	return untracked
 }
 
-var vmPendingCreateRunstates = []string{
-	string(skytap.VMRunstateBusy),
+// trackedNetworkInterfaces returns the flattened network interfaces whose ID is in the tracked ones, leaving out
+// the ones managed outside of the VM, such as by a skytap_network_interface. The adapter order is kept.
+func trackedNetworkInterfaces(networkInterfaces []interface{}, tracked []interface{}) []interface{} {
+	ids := make(map[string]bool)
+	for _, networkInterface := range tracked {
+		if id, ok := networkInterface.(map[string]interface{})["id"].(string); ok {
+			ids[id] = true
+		}
+	}
+	result := make([]interface{}, 0)
+	for _, networkInterface := range networkInterfaces {
+		if ids[networkInterface.(map[string]interface{})["id"].(string)] {
+			result = append(result, networkInterface)
+		}
+	}
+	return result
 }
 
-var vmTargetCreateRunstates = []string{
-	string(skytap.VMRunstateStopped),
+// trackedPublishedServices returns the published services whose internal port is in the tracked set, leaving out
+// the ones managed outside of the VM, such as by a skytap_published_service
+func trackedPublishedServices(services []skytap.PublishedService, tracked *schema.Set) []skytap.PublishedService {
+	ports := make(map[int]bool)
+	for _, publishedService := range tracked.List() {
+		ports[publishedService.(map[string]interface{})["internal_port"].(int)] = true
+	}
+	result := make([]skytap.PublishedService, 0)
+	for _, publishedService := range services {
+		if publishedService.InternalPort != nil && ports[*publishedService.InternalPort] {
+			result = append(result, publishedService)
+		}
+	}
+	return result
 }
 
-var vmPendingUpdateRunstates = []string{
-	string(skytap.VMRunstateBusy),
+// excludeDisks returns the disks which are not in the excluded list
+func excludeDisks(disks []skytap.Disk, excluded []skytap.Disk) []skytap.Disk {
+	result := make([]skytap.Disk, 0)
+	for _, disk := range disks {
+		found := false
+		for _, e := range excluded {
+			if *e.ID == *disk.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, disk)
+		}
+	}
+	return result
+}
+
+// matchDisks pairs each configured disk, by name, with the disk of the previous state it updates. Skytap does not
+// store disk names, so the disks of an imported VM have none. Those are matched to the configured disks of the same
+// size, or else in LUN order to a configured disk at least as large, so that the existing disks are kept instead of
+// being replaced by blank ones.
+func matchDisks(oldDisks *schema.Set, newDisks *schema.Set) map[string]map[string]interface{} {
+	named := make(map[string]map[string]interface{})
+	unnamed := make([]map[string]interface{}, 0)
+	for _, v := range oldDisks.List() {
+		disk := v.(map[string]interface{})
+		if name := disk["name"].(string); name != "" {
+			named[name] = disk
+		} else if disk["id"].(string) != "" {
+			unnamed = append(unnamed, disk)
+		}
+	}
+	sort.SliceStable(unnamed, func(i, j int) bool {
+		return diskLUN(unnamed[i]) < diskLUN(unnamed[j])
+	})
+
+	matched := make(map[string]map[string]interface{})
+	pending := make([]map[string]interface{}, 0)
+	for _, v := range newDisks.List() {
+		disk := v.(map[string]interface{})
+		name := disk["name"].(string)
+		if oldDisk, ok := named[name]; ok {
+			matched[name] = oldDisk
+		} else {
+			pending = append(pending, disk)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i]["name"].(string) < pending[j]["name"].(string)
+	})
+
+	used := make([]bool, len(unnamed))
+	match := func(fits func(oldSize int, newSize int) bool) {
+		for _, disk := range pending {
+			name := disk["name"].(string)
+			if _, ok := matched[name]; ok {
+				continue
+			}
+			for idx, oldDisk := range unnamed {
+				if !used[idx] && fits(oldDisk["size"].(int), disk["size"].(int)) {
+					matched[name] = oldDisk
+					used[idx] = true
+					break
+				}
+			}
+		}
+	}
+	match(func(oldSize int, newSize int) bool { return oldSize == newSize })
+	match(func(oldSize int, newSize int) bool { return oldSize <= newSize })
+	return matched
+}
+
+// vmDiskHash hashes a disk by its name and size. The disks of an imported VM have no name, so their ID is hashed too,
+// otherwise the disks of the same size would collapse into one.
+func vmDiskHash(v interface{}) int {
+	disk := v.(map[string]interface{})
+	name, _ := disk["name"].(string)
+	id, _ := disk["id"].(string)
+	if name == "" && id != "" {
+		return hashcode.String(fmt.Sprintf("%v-%s", disk["size"], id))
+	}
+	return schema.HashResource(vmDiskResource())(v)
+}
+
+// diskLUN returns the logical unit number of a disk, ordering the disks whose LUN is unknown last
+func diskLUN(disk map[string]interface{}) int {
+	lun, err := strconv.Atoi(fmt.Sprint(disk["lun"]))
+	if err != nil {
+		return int(^uint(0) >> 1)
+	}
+	return lun
 }
 
-var vmPendingUpdateRunstateAfterCreate = []string{
+var vmPendingCreateRunstates = []string{
 	string(skytap.VMRunstateBusy),
+}
+
+var vmTargetCreateRunstates = []string{
 	string(skytap.VMRunstateStopped),
 }
 
-var vmTargetUpdateRunstateAfterCreate = []string{
-	string(skytap.VMRunstateRunning),
+var vmPendingUpdateRunstates = []string{
+	string(skytap.VMRunstateBusy),
 }
 
 var vmTargetUpdateRunstates = []string{
@@ -951,28 +1674,51 @@ var vmTargetUpdateRunstates = []string{
 	string(skytap.VMRunstateHalted),
 }
 
-func getVMPendingUpdateRunstates(running bool) []string {
-	if running {
-		return vmPendingUpdateRunstateAfterCreate
+// getVMPendingUpdateRunstates returns the runstates to wait through. When a runstate has been requested
+// every other runstate is pending, as the VM might not have left its previous runstate yet.
+func getVMPendingUpdateRunstates(runstate skytap.VMRunstate) []string {
+	if runstate == "" {
+		return vmPendingUpdateRunstates
+	}
+	target := getVMTargetUpdateRunstates(runstate)
+	pending := []string{string(skytap.VMRunstateBusy)}
+	for _, r := range vmTargetUpdateRunstates {
+		isTarget := false
+		for _, t := range target {
+			if t == r {
+				isTarget = true
+				break
+			}
+		}
+		if !isTarget {
+			pending = append(pending, r)
+		}
 	}
-	return vmPendingUpdateRunstates
+	return pending
 }
 
-func getVMTargetUpdateRunstates(running bool) []string {
-	if running {
-		return vmTargetUpdateRunstateAfterCreate
+// getVMTargetUpdateRunstates returns the runstates reached once the requested runstate is applied, or any
+// settled runstate if none was requested. Halting shuts the VM down through the guest OS, leaving it stopped.
+func getVMTargetUpdateRunstates(runstate skytap.VMRunstate) []string {
+	switch runstate {
+	case "":
+		return vmTargetUpdateRunstates
+	case skytap.VMRunstateHalted:
+		return []string{string(skytap.VMRunstateHalted), string(skytap.VMRunstateStopped)}
 	}
-	return vmTargetUpdateRunstates
+	return []string{string(runstate)}
+}
+
+// vmRunstateSuppress ignores a `stopped` VM when it was requested to be `halted`
+func vmRunstateSuppress(_, old, new string, _ *schema.ResourceData) bool {
+	return new == string(skytap.VMRunstateHalted) && old == string(skytap.VMRunstateStopped)
 }
 
 func vmRunstateRefreshFunc(
-	ctx context.Context, d *schema.ResourceData, meta interface{}) resource.StateRefreshFunc {
+	ctx context.Context, meta interface{}, environmentID string, id string) resource.StateRefreshFunc {
 	return func() (interface{}, string, error) {
 		client := meta.(*SkytapClient).vmsClient
 
-		id := d.Id()
-		environmentID := d.Get("environment_id").(string)
-
 		log.Printf("[DEBUG] retrieving VM: %s", id)
 		vm, err := client.Get(ctx, environmentID, id)
 
@@ -986,11 +1732,37 @@ func vmRunstateRefreshFunc(
 	}
 }
 
+// waitForVMRunstate waits until the VM settles in the requested runstate, or in any runstate if none is given
+func waitForVMRunstate(ctx context.Context, d *schema.ResourceData, meta interface{}, runstate skytap.VMRunstate,
+	schemaTimeout string) error {
+	return waitForVMRunstateByID(ctx, meta, d.Get("environment_id").(string), d.Id(), runstate, d.Timeout(schemaTimeout))
+}
+
+// waitForVMRunstateByID is waitForVMRunstate for a VM which is not managed by the resource data
+func waitForVMRunstateByID(ctx context.Context, meta interface{}, environmentID string, id string, runstate skytap.VMRunstate,
+	timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Pending:    getVMPendingUpdateRunstates(runstate),
+		Target:     getVMTargetUpdateRunstates(runstate),
+		Refresh:    vmRunstateRefreshFunc(ctx, meta, environmentID, id),
+		Timeout:    timeout,
+		MinTimeout: minTimeout * time.Second,
+		Delay:      delay * time.Second,
+	}
+
+	log.Printf("[INFO] Waiting for VM (%s) to complete", id)
+	_, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error waiting for VM (%s) to complete: %s", id, err)
+	}
+	return nil
+}
+
 func waitForVMStopped(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	stateConf := &resource.StateChangeConf{
 		Pending:    vmPendingCreateRunstates,
 		Target:     vmTargetCreateRunstates,
-		Refresh:    vmRunstateRefreshFunc(ctx, d, meta),
+		Refresh:    vmRunstateRefreshFunc(ctx, meta, d.Get("environment_id").(string), d.Id()),
 		Timeout:    d.Timeout(schema.TimeoutCreate),
 		MinTimeout: minTimeout * time.Second,
 		Delay:      delay * time.Second,
@@ -1035,6 +1807,18 @@ func vmCreate(ctx context.Context, d *schema.ResourceData, meta interface{}, env
 	templateID := d.Get("template_id").(string)
 	templateVMID := d.Get("vm_id").(string)
 
+	// the template ID was not known when planning, so the template VM name is resolved now
+	if templateVMID == "" {
+		var err error
+		templateVMID, err = getTemplateVMID(ctx, meta, templateID, d.Get("template_vm_name").(string))
+		if err != nil {
+			return "", err
+		}
+		if err = d.Set("vm_id", templateVMID); err != nil {
+			return "", err
+		}
+	}
+
 	// create the VM
 	createOpts := skytap.CreateVMRequest{
 		TemplateID: templateID,
@@ -1053,19 +1837,195 @@ func vmCreate(ctx context.Context, d *schema.ResourceData, meta interface{}, env
 	return *vm.ID, nil
 }
 
-func forceRunning(ctx context.Context, meta interface{}, environmentID string, id string) error {
+// vmSettingsRequest updates the VM settings which are not supported by the SDK
+type vmSettingsRequest struct {
+	DesktopResizable *bool                      `json:"desktop_resizable,omitempty"`
+	LocalMouseCursor *bool                      `json:"local_mouse_cursor,omitempty"`
+	HardwareVersion  *int                       `json:"hardware_version,omitempty"`
+	Hardware         *vmHardwareSettingsRequest `json:"hardware,omitempty"`
+}
+
+// vmHardwareSettingsRequest holds the hardware settings, which can only be changed while the VM is stopped
+type vmHardwareSettingsRequest struct {
+	CpusPerSocket        *int    `json:"cpus_per_socket,omitempty"`
+	NestedVirtualization *bool   `json:"nested_virtualization,omitempty"`
+	TimeSyncEnabled      *bool   `json:"time_sync_enabled,omitempty"`
+	CopyPasteEnabled     *bool   `json:"copy_paste_enabled,omitempty"`
+	VncKeymap            *string `json:"vnc_keymap,omitempty"`
+	RTCStartTime         *string `json:"rtc_start_time,omitempty"`
+	InstanceType         *string `json:"instance_type,omitempty"`
+}
+
+// expandVMSettings builds the request for the settings selected by include, or returns nil if there are none
+func expandVMSettings(d *schema.ResourceData, include func(string) bool) *vmSettingsRequest {
+	opts := &vmSettingsRequest{}
+	hardware := &vmHardwareSettingsRequest{}
+	updated := false
+	hardwareUpdated := false
+
+	if include("desktop_resizable") {
+		opts.DesktopResizable = utils.Bool(d.Get("desktop_resizable").(bool))
+		updated = true
+	}
+	if include("local_mouse_cursor") {
+		opts.LocalMouseCursor = utils.Bool(d.Get("local_mouse_cursor").(bool))
+		updated = true
+	}
+	if v, ok := d.GetOk("hardware_version"); ok && include("hardware_version") {
+		opts.HardwareVersion = utils.Int(v.(int))
+		updated = true
+	}
+	if include("cpus_per_socket") {
+		hardware.CpusPerSocket = utils.Int(d.Get("cpus_per_socket").(int))
+		hardwareUpdated = true
+	}
+	if include("nested_virtualization") {
+		hardware.NestedVirtualization = utils.Bool(d.Get("nested_virtualization").(bool))
+		hardwareUpdated = true
+	}
+	if include("time_sync_enabled") {
+		hardware.TimeSyncEnabled = utils.Bool(d.Get("time_sync_enabled").(bool))
+		hardwareUpdated = true
+	}
+	if include("copy_paste_enabled") {
+		hardware.CopyPasteEnabled = utils.Bool(d.Get("copy_paste_enabled").(bool))
+		hardwareUpdated = true
+	}
+	if v, ok := d.GetOk("vnc_keymap"); ok && include("vnc_keymap") {
+		hardware.VncKeymap = utils.String(v.(string))
+		hardwareUpdated = true
+	}
+	if v, ok := d.GetOk("rtc_start_time"); ok && include("rtc_start_time") {
+		hardware.RTCStartTime = utils.String(v.(string))
+		hardwareUpdated = true
+	}
+	if v, ok := d.GetOk("instance_type"); ok && include("instance_type") {
+		hardware.InstanceType = utils.String(v.(string))
+		hardwareUpdated = true
+	}
+
+	if hardwareUpdated {
+		opts.Hardware = hardware
+	}
+	if !updated && !hardwareUpdated {
+		return nil
+	}
+	return opts
+}
+
+// updateVMSettings applies the settings selected by include. A running or suspended VM is stopped while
+// the hardware settings or hardware version are changed, then returned to its runstate.
+func updateVMSettings(ctx context.Context, d *schema.ResourceData, meta interface{}, include func(string) bool,
+	schemaTimeout string) error {
+	opts := expandVMSettings(d, include)
+	if opts == nil {
+		return nil
+	}
+
+	environmentID := d.Get("environment_id").(string)
+	id := d.Id()
+
+	update := func() error {
+		log.Printf("[INFO] VM (%s) updating settings", id)
+		log.Printf("[TRACE] VM updating settings: %v", spew.Sdump(opts))
+		path := fmt.Sprintf("v2/configurations/%s/vms/%s.json", environmentID, id)
+		if err := meta.(*SkytapClient).apiClient.do(ctx, "PUT", path, opts, nil); err != nil {
+			return fmt.Errorf("error updating VM (%s) settings: %v", id, err)
+		}
+		return waitForVMRunstate(ctx, d, meta, "", schemaTimeout)
+	}
+
+	if opts.Hardware != nil || opts.HardwareVersion != nil {
+		return updateStoppedVM(ctx, meta, environmentID, id, d.Timeout(schemaTimeout), update)
+	}
+	return update()
+}
+
+// vmStoppedMutexKV serializes the updates made to each stopped VM. Otherwise a concurrent update could restart the
+// VM before another one is made, or record the stopped runstate as the one to return the VM to.
+var vmStoppedMutexKV = utils.NewMutexKV()
+
+// updateStoppedVM stops a running or suspended VM, calls update and then returns the VM to its runstate.
+// Changes to the hardware and network adapters of a VM can only be made while it is stopped.
+func updateStoppedVM(ctx context.Context, meta interface{}, environmentID string, id string, timeout time.Duration,
+	update func() error) error {
+	vmStoppedMutexKV.Lock(id)
+	defer vmStoppedMutexKV.Unlock(id)
+
+	vm, err := meta.(*SkytapClient).vmsClient.Get(ctx, environmentID, id)
+	if err != nil {
+		return fmt.Errorf("error retrieving VM (%s): %v", id, err)
+	}
+	var runstate skytap.VMRunstate
+	if vm.Runstate != nil && (*vm.Runstate == skytap.VMRunstateRunning || *vm.Runstate == skytap.VMRunstateSuspended) {
+		runstate = *vm.Runstate
+		if err = changeVMRunstateByID(ctx, meta, environmentID, id, skytap.VMRunstateStopped, timeout); err != nil {
+			return err
+		}
+	}
+
+	if err = update(); err != nil {
+		return err
+	}
+
+	if runstate != "" {
+		return changeVMRunstateByID(ctx, meta, environmentID, id, runstate, timeout)
+	}
+	return nil
+}
+
+// changeVMRunstate moves the VM to the requested runstate and waits for it to settle.
+// A stopped VM cannot be suspended, so it is started first.
+func changeVMRunstate(ctx context.Context, d *schema.ResourceData, meta interface{}, runstate skytap.VMRunstate,
+	schemaTimeout string) error {
+	return changeVMRunstateByID(ctx, meta, d.Get("environment_id").(string), d.Id(), runstate, d.Timeout(schemaTimeout))
+}
+
+// changeVMRunstateByID is changeVMRunstate for a VM which is not managed by the resource data
+func changeVMRunstateByID(ctx context.Context, meta interface{}, environmentID string, id string, runstate skytap.VMRunstate,
+	timeout time.Duration) error {
+	client := meta.(*SkytapClient).vmsClient
+
+	vm, err := client.Get(ctx, environmentID, id)
+	if err != nil {
+		return fmt.Errorf("error retrieving VM (%s): %v", id, err)
+	}
+	current := skytap.VMRunstate("")
+	if vm.Runstate != nil {
+		current = *vm.Runstate
+	}
+	if current == runstate || (runstate == skytap.VMRunstateHalted && current == skytap.VMRunstateStopped) {
+		return nil
+	}
+
+	if runstate == skytap.VMRunstateSuspended && current == skytap.VMRunstateStopped {
+		if err = updateVMRunstate(ctx, meta, environmentID, id, skytap.VMRunstateRunning); err != nil {
+			return err
+		}
+		if err = waitForVMRunstateByID(ctx, meta, environmentID, id, skytap.VMRunstateRunning, timeout); err != nil {
+			return err
+		}
+	}
+
+	if err = updateVMRunstate(ctx, meta, environmentID, id, runstate); err != nil {
+		return err
+	}
+	return waitForVMRunstateByID(ctx, meta, environmentID, id, runstate, timeout)
+}
+
+func updateVMRunstate(ctx context.Context, meta interface{}, environmentID string, id string, runstate skytap.VMRunstate) error {
 	client := meta.(*SkytapClient).vmsClient
 
 	opts := skytap.UpdateVMRequest{}
-	opts.Runstate = utils.VMRunstate(skytap.VMRunstateRunning)
-	log.Printf("[INFO] VM starting: %s", id)
-	log.Printf("[TRACE] VM starting: %v", spew.Sdump(opts))
+	opts.Runstate = utils.VMRunstate(runstate)
+	log.Printf("[INFO] VM (%s) changing runstate to: %s", id, runstate)
+	log.Printf("[TRACE] VM changing runstate: %v", spew.Sdump(opts))
 	vm, err := client.Update(ctx, environmentID, id, &opts)
 	if err != nil {
-		return fmt.Errorf("error starting VM (%s): %v", id, err)
+		return fmt.Errorf("error changing VM (%s) runstate: %v", id, err)
 	}
-	log.Printf("[INFO] started VM: %s", id)
-	log.Printf("[TRACE] started VM: %v", spew.Sdump(vm))
+	log.Printf("[INFO] VM (%s) changed runstate to: %s", id, runstate)
+	log.Printf("[TRACE] VM changed runstate: %v", spew.Sdump(vm))
 	return nil
 }
 
@@ -0,0 +1,328 @@
+package skytap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/skytap/skytap-sdk-go/skytap"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func resourceSkytapVMDisk() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSkytapVMDiskCreate,
+		ReadContext:   resourceSkytapVMDiskRead,
+		UpdateContext: resourceSkytapVMDiskUpdate,
+		DeleteContext: resourceSkytapVMDiskDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateVMChild,
+		},
+
+		CustomizeDiff: resourceSkytapVMDiskSizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"environment_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the environment containing the VM",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"vm_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "ID of the VM you want to add the disk to",
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "A name for the disk. Skytap does not store disk names, so it is only kept in the Terraform state",
+				ValidateFunc: validation.StringLenBetween(1, 33),
+			},
+
+			"size": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "The size of the disk specified in MiB. The minimum disk size is 2048 MiB; the maximum is 2,096,128 MiB (1.999 TiB). The disk can be grown in place, but not shrunk",
+				ValidateFunc: validation.IntBetween(2048, 2096128),
+			},
+
+			// computed attributes
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of disk",
+			},
+
+			"controller": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The disk controller",
+			},
+
+			"lun": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The logical unit number (LUN) of the disk",
+			},
+		},
+	}
+}
+
+// resourceSkytapVMDiskSizeDiff rejects shrinking the disk when planning, as Skytap can only grow disks
+func resourceSkytapVMDiskSizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("size") {
+		return nil
+	}
+	sizeOld, sizeNew := d.GetChange("size")
+	name := d.Get("name").(string)
+	if name == "" {
+		name = d.Id()
+	}
+	return checkDiskNotShrunk(sizeOld.(int), sizeNew.(int), name)
+}
+
+func resourceSkytapVMDiskCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	size := d.Get("size").(int)
+
+	if err := waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	var before []skytap.Disk
+	vm, err := updateVMDisks(ctx, meta, environmentID, vmID, func(vm *skytap.VM) *skytap.UpdateDisks {
+		before = vm.Hardware.Disks
+		diskIDs := vmDiskIdentification(vm, nil)
+		diskIDs = append(diskIDs, skytap.DiskIdentification{Size: utils.Int(size)})
+		return &skytap.UpdateDisks{
+			NewDisks:           []int{size},
+			DiskIdentification: diskIDs,
+		}
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	added := excludeDisks(vm.Hardware.Disks[1:], before)
+	if len(added) != 1 {
+		return diag.Errorf("error finding the disk added to VM (%s): %d new disks found", vmID, len(added))
+	}
+	d.SetId(*added[0].ID)
+
+	log.Printf("[INFO] VM disk created: %s", d.Id())
+
+	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutCreate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSkytapVMDiskRead(ctx, d, meta)
+}
+
+func resourceSkytapVMDiskRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*SkytapClient).vmsClient
+
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	id := d.Id()
+
+	log.Printf("[INFO] retrieving VM disk: %s", id)
+	vm, err := client.Get(ctx, environmentID, vmID)
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] VM (%s) was not found - removing disk (%s) from state", vmID, id)
+			d.SetId("")
+			return nil
+		}
+
+		return diag.Errorf("error retrieving VM (%s): %v", vmID, err)
+	}
+
+	disk := getVMDisk(vm, id)
+	if disk == nil {
+		log.Printf("[DEBUG] VM disk (%s) was not found - removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	err = d.Set("environment_id", environmentID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("vm_id", vmID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("size", disk.Size)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("type", disk.Type)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("controller", disk.Controller)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("lun", disk.LUN)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] VM disk retrieved: %s", id)
+	log.Printf("[TRACE] VM disk retrieved: %v", spew.Sdump(disk))
+
+	return nil
+}
+
+func resourceSkytapVMDiskUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	id := d.Id()
+
+	// the name is only kept in the state
+	if !d.HasChange("size") {
+		return resourceSkytapVMDiskRead(ctx, d, meta)
+	}
+
+	size := d.Get("size").(int)
+	_, err := updateVMDisks(ctx, meta, environmentID, vmID, func(vm *skytap.VM) *skytap.UpdateDisks {
+		diskIDs := vmDiskIdentification(vm, nil)
+		for idx := range diskIDs {
+			if *diskIDs[idx].ID == id {
+				diskIDs[idx].Size = utils.Int(size)
+			}
+		}
+		return &skytap.UpdateDisks{
+			DiskIdentification: diskIDs,
+		}
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] VM disk updated: %s", id)
+
+	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutUpdate); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSkytapVMDiskRead(ctx, d, meta)
+}
+
+func resourceSkytapVMDiskDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	environmentID := d.Get("environment_id").(string)
+	vmID := d.Get("vm_id").(string)
+	id := d.Id()
+
+	log.Printf("[INFO] destroying VM disk: %s", id)
+	found := true
+	_, err := updateVMDisks(ctx, meta, environmentID, vmID, func(vm *skytap.VM) *skytap.UpdateDisks {
+		if getVMDisk(vm, id) == nil {
+			found = false
+			return nil
+		}
+		// the SDK removes the disks which are not identified
+		return &skytap.UpdateDisks{
+			DiskIdentification: vmDiskIdentification(vm, func(disk skytap.Disk) bool { return *disk.ID == id }),
+		}
+	})
+	if err != nil {
+		if utils.ResponseErrorIsNotFound(err) {
+			log.Printf("[DEBUG] VM (%s) was not found - assuming disk (%s) removed", vmID, id)
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if !found {
+		log.Printf("[DEBUG] VM disk (%s) was not found - assuming removed", id)
+		return nil
+	}
+
+	if err = waitForEnvironmentReady(ctx, d, meta, environmentID, schema.TimeoutDelete); err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] VM disk destroyed: %s", id)
+
+	return nil
+}
+
+// updateVMDisks retrieves the VM and applies the disk changes built from it. The changes to the disks of each VM are
+// serialized, as every update must identify all the disks the VM keeps. No update is made if build returns nil.
+func updateVMDisks(ctx context.Context, meta interface{}, environmentID string, vmID string,
+	build func(*skytap.VM) *skytap.UpdateDisks) (*skytap.VM, error) {
+	client := meta.(*SkytapClient).vmsClient
+
+	vmDiskMutexKV.Lock(vmID)
+	defer vmDiskMutexKV.Unlock(vmID)
+
+	vm, err := client.Get(ctx, environmentID, vmID)
+	if err != nil {
+		return nil, err
+	}
+	disks := build(vm)
+	if disks == nil {
+		return vm, nil
+	}
+
+	opts := skytap.UpdateVMRequest{
+		Hardware: &skytap.UpdateHardware{
+			UpdateDisks: disks,
+		},
+	}
+	log.Printf("[INFO] VM (%s) updating disks", vmID)
+	log.Printf("[TRACE] VM updating disks: %v", spew.Sdump(opts))
+	vm, err = client.Update(ctx, environmentID, vmID, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("error updating VM (%s) disks: %v", vmID, err)
+	}
+	log.Printf("[INFO] VM (%s) updated disks", vmID)
+	log.Printf("[TRACE] VM updated disks: %v", spew.Sdump(vm))
+	return vm, nil
+}
+
+// vmDiskIdentification identifies the disks of the VM, other than the OS disk and the disks matching exclude,
+// keeping their current size
+func vmDiskIdentification(vm *skytap.VM, exclude func(skytap.Disk) bool) []skytap.DiskIdentification {
+	diskIDs := make([]skytap.DiskIdentification, 0)
+	for idx, disk := range vm.Hardware.Disks {
+		if idx == 0 || (exclude != nil && exclude(disk)) {
+			continue
+		}
+		diskIDs = append(diskIDs, skytap.DiskIdentification{ID: disk.ID, Size: disk.Size, Name: disk.Name})
+	}
+	return diskIDs
+}
+
+// getVMDisk returns the disk of the VM with the given ID, or nil if there is none
+func getVMDisk(vm *skytap.VM, id string) *skytap.Disk {
+	if vm.Hardware == nil {
+		return nil
+	}
+	for idx := range vm.Hardware.Disks {
+		if vm.Hardware.Disks[idx].ID != nil && *vm.Hardware.Disks[idx].ID == id {
+			return &vm.Hardware.Disks[idx]
+		}
+	}
+	return nil
+}
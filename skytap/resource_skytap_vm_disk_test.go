@@ -0,0 +1,149 @@
+package skytap
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/skytap/skytap-sdk-go/skytap"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
+)
+
+func TestAccSkytapVMDisk_Basic(t *testing.T) {
+	templateID, vmID, newEnvTemplateID := setupEnvironment()
+	uniqueSuffixEnv := acctest.RandInt()
+	var disk skytap.Disk
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapVMDiskConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, 2048),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapVMDiskExists("skytap_vm_disk.data", &disk),
+					resource.TestCheckResourceAttr("skytap_vm_disk.data", "name", "data"),
+					resource.TestCheckResourceAttr("skytap_vm_disk.data", "size", "2048"),
+					resource.TestCheckResourceAttrSet("skytap_vm_disk.data", "controller"),
+					resource.TestCheckResourceAttrSet("skytap_vm_disk.data", "lun"),
+					resource.TestCheckResourceAttrSet("skytap_vm_disk.data", "type"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "disk.#", "1"),
+				),
+			},
+			{
+				Config: testAccSkytapVMDiskConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, 4096),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapVMDiskExists("skytap_vm_disk.data", &disk),
+					resource.TestCheckResourceAttr("skytap_vm_disk.data", "size", "4096"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "disk.#", "1"),
+				),
+			},
+			{
+				Config:      testAccSkytapVMDiskConfig(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, 2048),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`cannot shrink volume`),
+			},
+			{
+				ResourceName:            "skytap_vm_disk.data",
+				ImportState:             true,
+				ImportStateIdFunc:       testAccSkytapVMChildImportStateIDFunc("skytap_vm_disk.data"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"name"},
+			},
+		},
+	})
+}
+
+func TestVMDiskIdentification(t *testing.T) {
+	vm := &skytap.VM{
+		Hardware: &skytap.Hardware{
+			Disks: []skytap.Disk{
+				{ID: utils.String("disk-os"), Size: utils.Int(30720)},
+				{ID: utils.String("disk-1"), Size: utils.Int(2048)},
+				{ID: utils.String("disk-2"), Size: utils.Int(4096)},
+			},
+		},
+	}
+
+	diskIDs := vmDiskIdentification(vm, nil)
+	assert.Len(t, diskIDs, 2)
+	assert.Equal(t, "disk-1", *diskIDs[0].ID)
+	assert.Equal(t, 2048, *diskIDs[0].Size)
+	assert.Equal(t, "disk-2", *diskIDs[1].ID)
+
+	diskIDs = vmDiskIdentification(vm, func(disk skytap.Disk) bool { return *disk.ID == "disk-1" })
+	assert.Len(t, diskIDs, 1)
+	assert.Equal(t, "disk-2", *diskIDs[0].ID)
+
+	assert.Equal(t, 4096, *getVMDisk(vm, "disk-2").Size)
+	assert.Nil(t, getVMDisk(vm, "disk-3"))
+}
+
+func TestUntrackedVMDisks(t *testing.T) {
+	vm := &skytap.VM{
+		Hardware: &skytap.Hardware{
+			Disks: []skytap.Disk{
+				{ID: utils.String("disk-os"), Size: utils.Int(30720)},
+				{ID: utils.String("disk-1"), Size: utils.Int(2048)},
+				{ID: utils.String("disk-2"), Size: utils.Int(4096)},
+			},
+		},
+	}
+	tracked := schema.NewSet(schema.HashResource(resourceSkytapVM().Schema["disk"].Elem.(*schema.Resource)), []interface{}{
+		map[string]interface{}{"name": "tracked", "size": 2048, "id": "disk-1"},
+	})
+
+	untracked := untrackedVMDisks(vm, tracked)
+	assert.Len(t, untracked, 1)
+	assert.Equal(t, "disk-2", *untracked[0].ID)
+
+	disks := excludeDisks(vm.Hardware.Disks, untracked)
+	assert.Len(t, disks, 2)
+	assert.Equal(t, "disk-os", *disks[0].ID)
+	assert.Equal(t, "disk-1", *disks[1].ID)
+}
+
+func testAccCheckSkytapVMDiskExists(name string, disk *skytap.Disk) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, err := getResource(s, name)
+		if err != nil {
+			return err
+		}
+
+		client := testAccProvider.Meta().(*SkytapClient).vmsClient
+		ctx := context.TODO()
+		vm, err := client.Get(ctx, rs.Primary.Attributes["environment_id"], rs.Primary.Attributes["vm_id"])
+		if err != nil {
+			return fmt.Errorf("VM (%s) was not found: %v", rs.Primary.Attributes["vm_id"], err)
+		}
+		found := getVMDisk(vm, rs.Primary.ID)
+		if found == nil {
+			return fmt.Errorf("VM disk (%s) was not found", rs.Primary.ID)
+		}
+
+		*disk = *found
+		return nil
+	}
+}
+
+func testAccSkytapVMDiskConfig(envTemplateID string, uniqueSuffixEnv int, templateID string, vmID string, size int) string {
+	return testAccSkytapVMConfigBlock(envTemplateID, uniqueSuffixEnv, templateID, vmID, "bar", "", `
+		disk {
+			name = "inline"
+			size = 2048
+		}`) + fmt.Sprintf(`
+	resource "skytap_vm_disk" "data" {
+		environment_id = "${skytap_environment.foo.id}"
+		vm_id          = "${skytap_vm.bar.id}"
+		name           = "data"
+		size           = %d
+	}`, size)
+}
@@ -0,0 +1,63 @@
+package skytap
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceSkytapVMResourceV0 is the schema of skytap_vm before the network interfaces became an ordered list
+func resourceSkytapVMResourceV0() *schema.Resource {
+	s := resourceSkytapVMSchema()
+	s["network_interface"] = &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Computed: true,
+		ForceNew: true,
+		Elem:     vmNetworkInterfaceResource(),
+	}
+	return &schema.Resource{
+		Schema: s,
+	}
+}
+
+// resourceSkytapVMStateUpgradeV0 turns the network interface set into a list in adapter order, with the first
+// interface as the primary
+func resourceSkytapVMStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	networkInterfaces, ok := rawState["network_interface"].([]interface{})
+	if !ok {
+		return rawState, nil
+	}
+
+	sort.SliceStable(networkInterfaces, func(i, j int) bool {
+		return networkInterfaceIndex(networkInterfaces[i]) < networkInterfaceIndex(networkInterfaces[j])
+	})
+	for idx, v := range networkInterfaces {
+		if networkInterface, ok := v.(map[string]interface{}); ok {
+			networkInterface["primary"] = idx == 0
+		}
+	}
+	rawState["network_interface"] = networkInterfaces
+
+	log.Printf("[DEBUG] upgraded %d network interfaces of VM (%v)", len(networkInterfaces), rawState["id"])
+	return rawState, nil
+}
+
+// networkInterfaceIndex returns the adapter index of a network interface, which ends its ID (for example,
+// `nic-1234567-12345678-0`). Interfaces whose index is unknown are ordered last.
+func networkInterfaceIndex(v interface{}) int {
+	networkInterface, ok := v.(map[string]interface{})
+	if !ok {
+		return int(^uint(0) >> 1)
+	}
+	id, _ := networkInterface["id"].(string)
+	index, err := strconv.Atoi(id[strings.LastIndex(id, "-")+1:])
+	if err != nil {
+		return int(^uint(0) >> 1)
+	}
+	return index
+}
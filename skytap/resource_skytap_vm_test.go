@@ -13,6 +13,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/skytap/skytap-sdk-go/skytap"
 	"github.com/stretchr/testify/assert"
@@ -77,6 +78,13 @@ func TestAccSkytapVM_Basic(t *testing.T) {
 					testAccCheckSkytapVMRunning(&vm),
 				),
 			},
+			{
+				ResourceName:            "skytap_vm.bar",
+				ImportState:             true,
+				ImportStateIdFunc:       testAccSkytapEnvironmentChildImportStateIDFunc("skytap_vm.bar"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"template_id", "vm_id"},
+			},
 		},
 	})
 }
@@ -168,6 +176,10 @@ func TestAccSkytapVM_Interface(t *testing.T) {
 					testAccCheckSkytapVMExists("skytap_environment.foo", "skytap_vm.bar", &vm),
 					testAccCheckSkytapInterfacesExists("skytap_environment.foo", "skytap_vm.bar", "skytap_network.baz", 2),
 					testAccCheckSkytapInterfaceAttributes(t, "skytap_environment.foo", "skytap_network.baz", &vm, skytap.NICTypeVMXNet3, []string{"192.168.0.10", "192.168.0.11"}, []string{"bloggs-web", "bloggs-web2"}),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "network_interface.0.ip", "192.168.0.10"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "network_interface.0.primary", "true"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "network_interface.1.ip", "192.168.0.11"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "network_interface.1.primary", "false"),
 				),
 			}, {
 				Config: testAccSkytapVMConfig_basic(newEnvTemplateID, uniqueSuffixEnv, `
@@ -644,6 +656,7 @@ func TestAccSkytapVMCPU_OutOfRange(t *testing.T) {
 				Config: testAccSkytapVMConfig_basic(newEnvTemplateID, uniqueSuffixEnv, "", templateID, vmID, "", "",
 					`cpus = 12
                               ram = 131072`),
+				PlanOnly:    true,
 				ExpectError: regexp.MustCompile(`the 'cpus' argument has been assigned \(12\) which is more than the maximum allowed \(8\) as defined by this VM`),
 			},
 		},
@@ -673,6 +686,394 @@ func TestAccSkytapVMCPU_OutOfRangeAfterUpdate(t *testing.T) {
 	})
 }
 
+func TestAccSkytapVM_Settings(t *testing.T) {
+	templateID, vmID, newEnvTemplateID := setupEnvironment()
+	uniqueSuffixEnv := acctest.RandInt()
+	var vm skytap.VM
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapVMConfigBlock(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, "test", "",
+					`cpus                  = 2
+					ram                   = 4096
+					cpus_per_socket       = 2
+					nested_virtualization = true
+					copy_paste_enabled    = false
+					desktop_resizable     = false`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapVMExists("skytap_environment.foo", "skytap_vm.bar", &vm),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "cpus_per_socket", "2"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "nested_virtualization", "true"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "copy_paste_enabled", "false"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "desktop_resizable", "false"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "runstate", "running"),
+				),
+			},
+			{
+				Config: testAccSkytapVMConfigBlock(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, "test", "",
+					`cpus                  = 2
+					ram                   = 4096
+					cpus_per_socket       = 1
+					nested_virtualization = true
+					copy_paste_enabled    = false
+					desktop_resizable     = false
+					time_sync_enabled     = false
+					rtc_start_time        = "2019/06/19 15:40:42"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapVMExists("skytap_environment.foo", "skytap_vm.bar", &vm),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "cpus_per_socket", "1"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "time_sync_enabled", "false"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "rtc_start_time", "2019/06/19 15:40:42"),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "runstate", "running"),
+					testAccCheckSkytapVMRunning(&vm),
+				),
+			},
+		},
+	})
+}
+
+func TestExpandVMSettings(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceSkytapVM().Schema, map[string]interface{}{
+		"desktop_resizable":     false,
+		"nested_virtualization": true,
+		"vnc_keymap":            "en-us",
+		"hardware_version":      19,
+	})
+	configured := func(key string) bool {
+		_, ok := d.GetOkExists(key)
+		return ok
+	}
+
+	opts := expandVMSettings(d, configured)
+	assert.NotNil(t, opts)
+	assert.False(t, *opts.DesktopResizable)
+	assert.Nil(t, opts.LocalMouseCursor)
+	assert.True(t, *opts.Hardware.NestedVirtualization)
+	assert.Equal(t, "en-us", *opts.Hardware.VncKeymap)
+	assert.Nil(t, opts.Hardware.CpusPerSocket)
+	assert.Equal(t, 19, *opts.HardwareVersion)
+
+	opts = expandVMSettings(d, func(key string) bool { return key == "desktop_resizable" })
+	assert.NotNil(t, opts)
+	assert.Nil(t, opts.Hardware)
+	assert.Nil(t, opts.HardwareVersion)
+
+	assert.Nil(t, expandVMSettings(d, func(string) bool { return false }))
+}
+
+func TestVMNetworkInterfacePrimaryDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"network_interface": resourceSkytapVMSchema()["network_interface"],
+		},
+		CustomizeDiff: resourceSkytapVMPrimaryNetworkInterfaceDiff,
+	}
+	state := &terraform.InstanceState{
+		ID: "vm-1",
+		Attributes: map[string]string{
+			"network_interface.#":                     "2",
+			"network_interface.0.interface_type":      "vmxnet3",
+			"network_interface.0.network_id":          "1",
+			"network_interface.0.ip":                  "192.168.0.10",
+			"network_interface.0.hostname":            "first",
+			"network_interface.0.primary":             "true",
+			"network_interface.0.id":                  "nic-1-2-0",
+			"network_interface.1.interface_type":      "vmxnet3",
+			"network_interface.1.network_id":          "1",
+			"network_interface.1.ip":                  "192.168.0.11",
+			"network_interface.1.hostname":            "second",
+			"network_interface.1.primary":             "false",
+			"network_interface.1.id":                  "nic-1-2-1",
+			"network_interface.0.published_service.#": "0",
+			"network_interface.1.published_service.#": "0",
+		},
+	}
+	networkInterfaces := func(first interface{}, second interface{}) *terraform.ResourceConfig {
+		networkInterface := func(ip string, hostname string, primary interface{}) map[string]interface{} {
+			m := map[string]interface{}{
+				"interface_type": "vmxnet3",
+				"network_id":     "1",
+				"ip":             ip,
+				"hostname":       hostname,
+			}
+			if primary != nil {
+				m["primary"] = primary
+			}
+			return m
+		}
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"network_interface": []interface{}{
+				networkInterface("192.168.0.10", "first", first),
+				networkInterface("192.168.0.11", "second", second),
+			},
+		})
+	}
+
+	for _, first := range []interface{}{nil, true, false} {
+		diff, err := r.Diff(context.Background(), state, networkInterfaces(first, nil), nil)
+		assert.NoError(t, err, "primary = %v", first)
+		assert.False(t, diff != nil && diff.RequiresNew(), "primary = %v", first)
+	}
+
+	_, err := r.Diff(context.Background(), state, networkInterfaces(nil, true), nil)
+	assert.Error(t, err)
+}
+
+func TestVMPublishedServiceDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"network_interface": resourceSkytapVMSchema()["network_interface"],
+		},
+	}
+	existing := r.Data(nil)
+	existing.SetId("456")
+	assert.NoError(t, existing.Set("network_interface", []interface{}{
+		map[string]interface{}{
+			"interface_type": "vmxnet3",
+			"network_id":     "1",
+			"ip":             "192.168.0.10",
+			"hostname":       "first",
+			"primary":        true,
+			"id":             "nic-1-2-0",
+			"published_service": []interface{}{
+				map[string]interface{}{"name": "ssh", "internal_port": 22, "id": "1", "external_ip": "203.0.113.10", "external_port": 26000},
+			},
+		},
+	}))
+	state := existing.State()
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"network_interface": []interface{}{
+			map[string]interface{}{
+				"interface_type": "vmxnet3",
+				"network_id":     "1",
+				"ip":             "192.168.0.10",
+				"hostname":       "first",
+				"published_service": []interface{}{
+					map[string]interface{}{"name": "secure-shell", "internal_port": 22},
+					map[string]interface{}{"name": "https", "internal_port": 443},
+				},
+			},
+		},
+	})
+	diff, err := r.Diff(context.Background(), state, config, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, diff)
+	assert.False(t, diff.RequiresNew())
+
+	d, err := schema.InternalMap(r.Schema).Data(state, diff)
+	assert.NoError(t, err)
+	old, new := d.GetChange("network_interface")
+	oldServices := publishedServicesByPort(old.([]interface{})[0].(map[string]interface{})["published_service"].(*schema.Set))
+	newServices := publishedServicesByPort(new.([]interface{})[0].(map[string]interface{})["published_service"].(*schema.Set))
+	assert.Equal(t, []int{22}, sortedPorts(oldServices))
+	assert.Equal(t, []int{22, 443}, sortedPorts(newServices))
+}
+
+func TestVMPublishedServiceDiffAfterImport(t *testing.T) {
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"network_interface": resourceSkytapVMSchema()["network_interface"],
+		},
+	}
+	// an imported VM has no published service names, as Skytap does not store them
+	imported := r.Data(nil)
+	imported.SetId("456")
+	assert.NoError(t, imported.Set("network_interface", flattenVMNetworkInterfaces([]skytap.Interface{
+		{
+			ID:        utils.String("nic-1-2-0"),
+			NICType:   utils.NICType(skytap.NICTypeVMXNet3),
+			NetworkID: utils.String("1"),
+			IP:        utils.String("192.168.0.10"),
+			Hostname:  utils.String("first"),
+			Services: []skytap.PublishedService{
+				{ID: utils.String("1"), InternalPort: utils.Int(22), ExternalIP: utils.String("203.0.113.10"), ExternalPort: utils.Int(26000)},
+			},
+		},
+	})))
+	state := imported.State()
+
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"network_interface": []interface{}{
+			map[string]interface{}{
+				"interface_type": "vmxnet3",
+				"network_id":     "1",
+				"ip":             "192.168.0.10",
+				"hostname":       "first",
+				"published_service": []interface{}{
+					map[string]interface{}{"name": "ssh", "internal_port": 22},
+				},
+			},
+		},
+	}), nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, diff)
+	assert.False(t, diff.RequiresNew())
+
+	// naming the service keeps it, as it is matched by its internal port
+	d, err := schema.InternalMap(r.Schema).Data(state, diff)
+	assert.NoError(t, err)
+	old, new := d.GetChange("network_interface")
+	oldServices := publishedServicesByPort(old.([]interface{})[0].(map[string]interface{})["published_service"].(*schema.Set))
+	newServices := publishedServicesByPort(new.([]interface{})[0].(map[string]interface{})["published_service"].(*schema.Set))
+	assert.Equal(t, []int{22}, sortedPorts(oldServices))
+	assert.Equal(t, []int{22}, sortedPorts(newServices))
+	assert.Equal(t, "", oldServices[22]["name"])
+	assert.Equal(t, "ssh", newServices[22]["name"])
+}
+
+func TestTrackedNetworkInterfaces(t *testing.T) {
+	networkInterfaces := flattenVMNetworkInterfaces([]skytap.Interface{
+		{ID: utils.String("nic-1-2-0"), NICType: utils.NICType(skytap.NICTypeVMXNet3)},
+		{ID: utils.String("nic-1-2-1"), NICType: utils.NICType(skytap.NICTypeVMXNet3)},
+		{ID: utils.String("nic-1-2-2"), NICType: utils.NICType(skytap.NICTypeVMXNet3)},
+	})
+	tracked := []interface{}{
+		map[string]interface{}{"id": "nic-1-2-0"},
+		map[string]interface{}{"id": "nic-1-2-2"},
+	}
+
+	result := trackedNetworkInterfaces(networkInterfaces, tracked)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "nic-1-2-0", result[0].(map[string]interface{})["id"])
+	assert.True(t, result[0].(map[string]interface{})["primary"].(bool))
+	assert.Equal(t, "nic-1-2-2", result[1].(map[string]interface{})["id"])
+	assert.False(t, result[1].(map[string]interface{})["primary"].(bool))
+}
+
+func TestTrackedPublishedServices(t *testing.T) {
+	services := []skytap.PublishedService{
+		{ID: utils.String("1"), InternalPort: utils.Int(22)},
+		{ID: utils.String("2"), InternalPort: utils.Int(8080)},
+		{ID: utils.String("3"), InternalPort: utils.Int(443)},
+	}
+	publishedService := resourceSkytapVMSchema()["network_interface"].Elem.(*schema.Resource).Schema["published_service"]
+	tracked := schema.NewSet(schema.HashResource(publishedService.Elem.(*schema.Resource)), []interface{}{
+		map[string]interface{}{"name": "ssh", "internal_port": 22},
+		map[string]interface{}{"name": "https", "internal_port": 443},
+	})
+
+	result := trackedPublishedServices(services, tracked)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "1", *result[0].ID)
+	assert.Equal(t, "3", *result[1].ID)
+
+	empty := schema.NewSet(schema.HashResource(publishedService.Elem.(*schema.Resource)), nil)
+	assert.Empty(t, trackedPublishedServices(services, empty))
+}
+
+func TestUpdateHardwareAfterImport(t *testing.T) {
+	// an imported VM has no disk names, as Skytap does not store them
+	r := resourceSkytapVM()
+	imported := r.Data(nil)
+	imported.SetId("456")
+	assert.NoError(t, imported.Set("environment_id", "123"))
+	assert.NoError(t, imported.Set("network_interface", []interface{}{}))
+	assert.NoError(t, imported.Set("disk", flattenDisks([]skytap.Disk{
+		{ID: utils.String("disk-os"), Size: utils.Int(30720), Type: utils.String("SCSI"), Controller: utils.String("0"), LUN: utils.String("0")},
+		{ID: utils.String("disk-1"), Size: utils.Int(4096), Type: utils.String("SCSI"), Controller: utils.String("0"), LUN: utils.String("1")},
+		{ID: utils.String("disk-2"), Size: utils.Int(2048), Type: utils.String("SCSI"), Controller: utils.String("0"), LUN: utils.String("2")},
+		{ID: utils.String("disk-3"), Size: utils.Int(2048), Type: utils.String("SCSI"), Controller: utils.String("0"), LUN: utils.String("3")},
+	})))
+	state := imported.State()
+
+	// the VM configuration names the disks, and grows one of them
+	sm := schema.InternalMap(r.Schema)
+	diff, err := sm.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"environment_id": "123",
+		"template_id":    "789",
+		"vm_id":          "101",
+		"disk": []interface{}{
+			map[string]interface{}{"name": "data", "size": 4096},
+			map[string]interface{}{"name": "logs", "size": 2048},
+			map[string]interface{}{"name": "swap", "size": 3072},
+		},
+	}), nil, nil, true)
+	assert.NoError(t, err)
+	assert.False(t, diff.RequiresNew())
+	d, err := sm.Data(state, diff)
+	assert.NoError(t, err)
+
+	hardware, err := updateHardware(d)
+	assert.NoError(t, err)
+	assert.Empty(t, hardware.UpdateDisks.NewDisks)
+	ids := make(map[string]string)
+	for _, disk := range hardware.UpdateDisks.DiskIdentification {
+		ids[*disk.Name] = *disk.ID
+	}
+	assert.Equal(t, map[string]string{"data": "disk-1", "logs": "disk-2", "swap": "disk-3"}, ids)
+}
+
+func TestCheckVMHardware(t *testing.T) {
+	hardware := &skytap.Hardware{
+		CPUs:          utils.Int(2),
+		CpusPerSocket: utils.Int(2),
+		MaxCPUs:       utils.Int(8),
+		RAM:           utils.Int(4096),
+		MaxRAM:        utils.Int(16384),
+		Disks:         []skytap.Disk{{Size: utils.Int(30720)}},
+	}
+
+	assert.NoError(t, checkVMHardware(hardware, 0, 0, 0, 0))
+	assert.NoError(t, checkVMHardware(hardware, 4, 0, 8192, 40960))
+	assert.EqualError(t, checkVMHardware(hardware, 12, 0, 0, 0), outOfRangeError("cpus", 12, 8).Error())
+	assert.EqualError(t, checkVMHardware(hardware, 0, 0, 32768, 0), outOfRangeError("ram", 32768, 16384).Error())
+	assert.EqualError(t, checkVMHardware(hardware, 6, 0, 0, 0), cpusExceedsRamError(6, 4).Error())
+	assert.EqualError(t, checkVMHardware(hardware, 0, 0, 1024, 0), cpusExceedsRamError(2, 1).Error())
+	assert.EqualError(t, checkVMHardware(hardware, 3, 0, 0, 0), cpusPerSocketError(3, 2).Error())
+	assert.NoError(t, checkVMHardware(hardware, 3, 1, 0, 0))
+	assert.EqualError(t, checkVMHardware(hardware, 4, 3, 0, 0), cpusPerSocketError(4, 3).Error())
+	assert.EqualError(t, checkVMHardware(hardware, 0, 0, 0, 20480), checkDiskNotShrunk(30720, 20480, "OS").Error())
+}
+
+func TestCheckVMHardwareVersion(t *testing.T) {
+	vm := &skytap.VM{
+		HardwareVersion:    utils.Int(11),
+		MaxHardwareVersion: utils.Int(19),
+		Hardware:           &skytap.Hardware{Upgradable: utils.Bool(true)},
+	}
+
+	assert.NoError(t, checkVMHardwareVersion(vm, 11))
+	assert.NoError(t, checkVMHardwareVersion(vm, 15))
+	assert.NoError(t, checkVMHardwareVersion(vm, 19))
+	assert.EqualError(t, checkVMHardwareVersion(vm, 10), hardwareVersionDowngradeError(10, 11).Error())
+	assert.EqualError(t, checkVMHardwareVersion(vm, 20), outOfRangeError("hardware_version", 20, 19).Error())
+
+	vm.Hardware.Upgradable = utils.Bool(false)
+	assert.NoError(t, checkVMHardwareVersion(vm, 11))
+	assert.Error(t, checkVMHardwareVersion(vm, 19))
+}
+
+func TestResourceSkytapVMStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"id": "123",
+		"network_interface": []interface{}{
+			map[string]interface{}{"id": "nic-1-2-10", "ip": "10.0.0.12"},
+			map[string]interface{}{"id": "nic-1-2-1", "ip": "10.0.0.11"},
+			map[string]interface{}{"id": "", "ip": "10.0.0.13"},
+			map[string]interface{}{"id": "nic-1-2-0", "ip": "10.0.0.10"},
+		},
+	}
+
+	upgraded, err := resourceSkytapVMStateUpgradeV0(context.TODO(), rawState, nil)
+	assert.NoError(t, err)
+	networkInterfaces := upgraded["network_interface"].([]interface{})
+	assert.Len(t, networkInterfaces, 4)
+	for idx, ip := range []string{"10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.13"} {
+		networkInterface := networkInterfaces[idx].(map[string]interface{})
+		assert.Equal(t, ip, networkInterface["ip"])
+		assert.Equal(t, idx == 0, networkInterface["primary"])
+	}
+
+	upgraded, err = resourceSkytapVMStateUpgradeV0(context.TODO(), map[string]interface{}{"id": "123"}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, upgraded, "network_interface")
+}
+
 func TestAccSkytapVMDisks_Create(t *testing.T) {
 	templateID, vmID, newEnvTemplateID := setupEnvironment()
 	uniqueSuffixEnv := acctest.RandInt()
@@ -997,6 +1398,92 @@ func TestAccSkytapVM_UserData(t *testing.T) {
 	})
 }
 
+func TestAccSkytapVM_Runstate(t *testing.T) {
+	templateID, vmID, newEnvTemplateID := setupEnvironment()
+	uniqueSuffixEnv := acctest.RandInt()
+	var vm skytap.VM
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSkytapVMConfigBlock(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, "test",
+					"", `runstate = "stopped"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapVMExists("skytap_environment.foo", "skytap_vm.bar", &vm),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "runstate", "stopped"),
+				),
+			},
+			{
+				Config: testAccSkytapVMConfigBlock(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, "test",
+					"", `runstate = "suspended"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapVMExists("skytap_environment.foo", "skytap_vm.bar", &vm),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "runstate", "suspended"),
+				),
+			},
+			{
+				Config: testAccSkytapVMConfigBlock(newEnvTemplateID, uniqueSuffixEnv, templateID, vmID, "test",
+					"", `runstate = "running"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapVMExists("skytap_environment.foo", "skytap_vm.bar", &vm),
+					resource.TestCheckResourceAttr("skytap_vm.bar", "runstate", "running"),
+					testAccCheckSkytapVMRunning(&vm),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkytapVM_TemplateVMName(t *testing.T) {
+	templateID, _, newEnvTemplateID := setupEnvironment()
+	uniqueSuffixEnv := acctest.RandInt()
+	var vm skytap.VM
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders,
+		CheckDestroy:      testAccCheckSkytapEnvironmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccSkytapVMConfig_templateVMName(newEnvTemplateID, uniqueSuffixEnv, templateID, `"tftest-missing-vm"`),
+				ExpectError: regexp.MustCompile(`has no VM named "tftest-missing-vm"`),
+			},
+			{
+				Config: testAccSkytapVMConfig_templateVMName(newEnvTemplateID, uniqueSuffixEnv, templateID,
+					"data.skytap_template.baz.vms.0.name"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSkytapVMExists("skytap_environment.foo", "skytap_vm.bar", &vm),
+					resource.TestCheckResourceAttrPair("skytap_vm.bar", "vm_id", "data.skytap_template.baz", "vms.0.id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSkytapVMConfig_templateVMName(envTemplateID string, uniqueSuffixEnv int, templateID string, templateVMName string) string {
+	return fmt.Sprintf(`
+	resource "skytap_environment" "foo" {
+		template_id = "%s"
+		name        = "%s-environment-%d"
+		description = "This is an environment to support a vm skytap terraform provider acceptance test"
+	}
+
+	data "skytap_template" "baz" {
+		id = "%s"
+	}
+
+	resource "skytap_vm" "bar" {
+		environment_id   = skytap_environment.foo.id
+		template_id      = data.skytap_template.baz.id
+		template_vm_name = %s
+		name             = "test"
+	}
+	`, envTemplateID, vmEnvironmentPrefix, uniqueSuffixEnv, templateID, templateVMName)
+}
+
 func TestAccSkytapVM_Labels(t *testing.T) {
 	templateID, vmID, newEnvTemplateID := setupEnvironment()
 	uniqueSuffixEnv := acctest.RandInt()
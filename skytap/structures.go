@@ -1,7 +1,10 @@
 package skytap
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -20,6 +23,16 @@ func flattenNetworkInterfaces(interfaces []skytap.Interface) []interface{} {
 	return results
 }
 
+// flattenVMNetworkInterfaces flattens the network interfaces of a skytap_vm, which are in adapter order,
+// marking the first one as the primary
+func flattenVMNetworkInterfaces(interfaces []skytap.Interface) []interface{} {
+	results := flattenNetworkInterfaces(interfaces)
+	for idx, v := range results {
+		v.(map[string]interface{})["primary"] = idx == 0
+	}
+	return results
+}
+
 func flattenNetworkInterface(v skytap.Interface) map[string]interface{} {
 	result := make(map[string]interface{})
 	result["id"] = *v.ID
@@ -36,6 +49,9 @@ func flattenNetworkInterface(v skytap.Interface) map[string]interface{} {
 	if len(v.Services) > 0 {
 		result["published_service"] = flattenPublishedServices(v.Services)
 	}
+	if len(v.SecondaryIPs) > 0 {
+		result["secondary_ips"] = flattenSecondaryIPs(v.SecondaryIPs)
+	}
 	return result
 }
 
@@ -112,7 +128,7 @@ func flattenLabels(labels []*skytap.Label) []interface{} {
 func flattenProjectIDs(projects []skytap.Project) []interface{} {
 	flattened := make([]interface{}, len(projects))
 	for i, v := range projects {
-		flattened[i] = v.ID
+		flattened[i] = *v.ID
 	}
 	return flattened
 }
@@ -125,10 +141,159 @@ func flattenProjectEnvironments(environments []skytap.ProjectEnvironment) []inte
 	return flattened
 }
 
+func flattenStages(stages []skytap.Stage) []interface{} {
+	sorted := make([]skytap.Stage, len(stages))
+	copy(sorted, stages)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Index == nil || sorted[j].Index == nil {
+			return false
+		}
+		return *sorted[i].Index < *sorted[j].Index
+	})
+
+	flattened := make([]interface{}, len(sorted))
+	for i, v := range sorted {
+		stage := map[string]interface{}{
+			"vm_ids": schema.NewSet(schema.HashString, flattenStrings(v.VMIDs)),
+		}
+		if v.DelayAfterFinishSeconds != nil {
+			stage["delay_after_finish_seconds"] = *v.DelayAfterFinishSeconds
+		}
+		flattened[i] = stage
+	}
+	return flattened
+}
+
+func flattenStagedExecution(execution *skytap.StagedExecution) []interface{} {
+	if execution == nil {
+		return []interface{}{}
+	}
+	result := map[string]interface{}{
+		"vm_ids": flattenStrings(execution.VMIDs),
+	}
+	if execution.ActionType != nil {
+		result["action_type"] = *execution.ActionType
+	}
+	if execution.CurrentStageIndex != nil {
+		result["current_stage_index"] = *execution.CurrentStageIndex
+	}
+	if execution.CurrentStageDelayAfterFinishSeconds != nil {
+		result["current_stage_delay_after_finish_seconds"] = *execution.CurrentStageDelayAfterFinishSeconds
+	}
+	if execution.CurrentStageFinishedAt != nil {
+		result["current_stage_finished_at"] = *execution.CurrentStageFinishedAt
+	}
+	return []interface{}{result}
+}
+
+func expandStages(stages []interface{}) []environmentStageRequest {
+	expanded := make([]environmentStageRequest, len(stages))
+	for i, v := range stages {
+		stage := v.(map[string]interface{})
+		vmIDs := make([]string, 0)
+		for _, id := range stage["vm_ids"].(*schema.Set).List() {
+			vmIDs = append(vmIDs, id.(string))
+		}
+		sort.Strings(vmIDs)
+		expanded[i] = environmentStageRequest{
+			DelayAfterFinishSeconds: stage["delay_after_finish_seconds"].(int),
+			VMIDs:                   vmIDs,
+		}
+	}
+	return expanded
+}
+
+func flattenSVMsByArchitecture(svms *skytap.SVMsByArchitecture) []interface{} {
+	if svms == nil {
+		return []interface{}{}
+	}
+	result := make(map[string]interface{})
+	if svms.X86 != nil {
+		result["x86"] = *svms.X86
+	}
+	if svms.Power != nil {
+		result["power"] = *svms.Power
+	}
+	return []interface{}{result}
+}
+
+func flattenAlerts(alerts []skytap.Alert) []interface{} {
+	flattened := make([]interface{}, len(alerts))
+	for i, v := range alerts {
+		flattened[i] = map[string]interface{}{
+			"id":           v.ID,
+			"display_type": v.DisplayType,
+			"dismissable":  v.Dismissable,
+			"message":      v.Message,
+		}
+	}
+	return flattened
+}
+
+func flattenEnvironmentVMs(vms []skytap.VM) []interface{} {
+	flattened := make([]interface{}, len(vms))
+	for i, v := range vms {
+		vm := make(map[string]interface{})
+		if v.ID != nil {
+			vm["id"] = *v.ID
+		}
+		if v.Name != nil {
+			vm["name"] = *v.Name
+		}
+		flattened[i] = vm
+	}
+	return flattened
+}
+
+func flattenEnvironmentNetworks(networks []skytap.Network) []interface{} {
+	flattened := make([]interface{}, len(networks))
+	for i, v := range networks {
+		network := make(map[string]interface{})
+		if v.ID != nil {
+			network["id"] = *v.ID
+		}
+		if v.Name != nil {
+			network["name"] = *v.Name
+		}
+		if v.Subnet != nil {
+			network["subnet"] = *v.Subnet
+		}
+		flattened[i] = network
+	}
+	return flattened
+}
+
+// flattenSecondaryIPs returns the addresses of the secondary IPs of a network interface
+func flattenSecondaryIPs(secondaryIPs []skytap.SecondaryIP) []interface{} {
+	flattened := make([]interface{}, 0, len(secondaryIPs))
+	for _, v := range secondaryIPs {
+		if v.Address != nil {
+			flattened = append(flattened, *v.Address)
+		}
+	}
+	return flattened
+}
+
+func expandStringSet(vs *schema.Set) []string {
+	expanded := make([]string, vs.Len())
+	for i, v := range vs.List() {
+		expanded[i] = v.(string)
+	}
+	return expanded
+}
+
+func flattenStrings(values []string) []interface{} {
+	flattened := make([]interface{}, len(values))
+	for i, v := range values {
+		flattened[i] = v
+	}
+	return flattened
+}
+
 func getVMNetworkInterface(id string, vm *skytap.VM) (*skytap.Interface, error) {
-	for _, networkInterface := range vm.Interfaces {
-		if *networkInterface.ID == id {
-			return &networkInterface, nil
+	for idx := range vm.Interfaces {
+		if *vm.Interfaces[idx].ID == id {
+			return &vm.Interfaces[idx], nil
 		}
 	}
 	return nil, fmt.Errorf("could not find network interface (%s) in the VM", id)
@@ -160,6 +325,108 @@ func caseInsensitiveSuppress(_, old, new string, _ *schema.ResourceData) bool {
 	return strings.ToLower(old) == strings.ToLower(new)
 }
 
+// importedSuppress is a helper function to suppress changes on arguments which are only used to build the
+// resource and are not returned by the API. Once imported they are empty, so the configured value is accepted
+// instead of forcing the resource to be recreated
+func importedSuppress(_, old, _ string, d *schema.ResourceData) bool {
+	return old == "" && d.Id() != ""
+}
+
+// parseEnvironmentChildID splits an import ID of the form `environment_id/id` into its parts
+func parseEnvironmentChildID(id string) (string, string, error) {
+	parts, err := parseImportID(id, "environment_id", "id")
+	if err != nil {
+		return "", "", err
+	}
+	return parts[0], parts[1], nil
+}
+
+// parseImportID splits an import ID made of the named parts separated by slashes
+func parseImportID(id string, names ...string) ([]string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != len(names) {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected %s", id, strings.Join(names, "/"))
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("unexpected format of ID (%s), expected %s", id, strings.Join(names, "/"))
+		}
+	}
+	return parts, nil
+}
+
+// importStateEnvironmentChild imports a resource contained within an environment using an ID
+// of the form `environment_id/id`
+func importStateEnvironmentChild(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	environmentID, id, err := parseEnvironmentChildID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Set("environment_id", environmentID)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(id)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// importStateVMChild imports a resource contained within a VM using an ID of the form `environment_id/vm_id/id`
+func importStateVMChild(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts, err := parseImportID(d.Id(), "environment_id", "vm_id", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Set("environment_id", parts[0])
+	if err != nil {
+		return nil, err
+	}
+	err = d.Set("vm_id", parts[1])
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(parts[2])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// importStateInterfaceChild imports a resource contained within a VM network interface using an ID of the form
+// `environment_id/vm_id/interface_id/id`
+func importStateInterfaceChild(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts, err := parseImportID(d.Id(), "environment_id", "vm_id", "interface_id", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Set("environment_id", parts[0])
+	if err != nil {
+		return nil, err
+	}
+	err = d.Set("vm_id", parts[1])
+	if err != nil {
+		return nil, err
+	}
+	err = d.Set("interface_id", parts[2])
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(parts[3])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// importStateIntID imports a resource whose ID must be an integer
+func importStateIntID(name string) schema.StateContextFunc {
+	return func(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+		if _, err := strconv.Atoi(d.Id()); err != nil {
+			return nil, fmt.Errorf("%s (%s) is not an integer: %v", name, d.Id(), err)
+		}
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
 // stringCaseSensitiveHash
 func stringCaseSensitiveHash(v interface{}) int {
 	return hashcode.String(strings.ToLower(v.(string)))
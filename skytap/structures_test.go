@@ -1,6 +1,7 @@
 package skytap
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -8,8 +9,11 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/skytap/skytap-sdk-go/skytap"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/terraform-providers/terraform-provider-skytap/skytap/utils"
 )
 
 func TestFlattenInterfaces(t *testing.T) {
@@ -40,6 +44,31 @@ func TestFlattenInterfaces(t *testing.T) {
 	}
 }
 
+func TestFlattenVMNetworkInterfaces(t *testing.T) {
+	response := string(readTestFile(t, "vm_interface_response.json"))
+
+	var interfaces []skytap.Interface
+	err := json.Unmarshal([]byte(response), &interfaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	networkInterfaces := flattenVMNetworkInterfaces(interfaces)
+	assert.Len(t, networkInterfaces, 2)
+	assert.Equal(t, "nic-20246343-38367563-0", networkInterfaces[0].(map[string]interface{})["id"])
+	assert.Equal(t, true, networkInterfaces[0].(map[string]interface{})["primary"])
+	assert.Equal(t, false, networkInterfaces[1].(map[string]interface{})["primary"])
+}
+
+func TestFlattenSecondaryIPs(t *testing.T) {
+	secondaryIPs := []skytap.SecondaryIP{
+		{ID: utils.String("ip-1"), Address: utils.String("10.0.0.5")},
+		{ID: utils.String("ip-2")},
+		{ID: utils.String("ip-3"), Address: utils.String("10.0.0.6")},
+	}
+	assert.Equal(t, []interface{}{"10.0.0.5", "10.0.0.6"}, flattenSecondaryIPs(secondaryIPs))
+	assert.Empty(t, flattenSecondaryIPs(nil))
+}
+
 func TestFlattenPublishedServices(t *testing.T) {
 
 	response := string(readTestFile(t, "vm_interface_services_response.json"))
@@ -111,6 +140,85 @@ func TestFlattenDisks(t *testing.T) {
 	}
 }
 
+func TestFlattenStages(t *testing.T) {
+	var stages []skytap.Stage
+	err := json.Unmarshal(readTestFile(t, "environment_stages.json"), &stages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flattened := flattenStages(stages)
+	assert.Len(t, flattened, 2)
+
+	first := flattened[0].(map[string]interface{})
+	assert.Equal(t, 120, first["delay_after_finish_seconds"])
+	assert.ElementsMatch(t, []interface{}{"1"}, first["vm_ids"].(*schema.Set).List())
+
+	second := flattened[1].(map[string]interface{})
+	assert.Equal(t, 0, second["delay_after_finish_seconds"])
+	assert.ElementsMatch(t, []interface{}{"2", "3"}, second["vm_ids"].(*schema.Set).List())
+
+	expanded := expandStages(flattened)
+	assert.Equal(t, []environmentStageRequest{
+		{DelayAfterFinishSeconds: 120, VMIDs: []string{"1"}},
+		{DelayAfterFinishSeconds: 0, VMIDs: []string{"2", "3"}},
+	}, expanded)
+}
+
+func TestFlattenStagedExecution(t *testing.T) {
+	assert.Empty(t, flattenStagedExecution(nil))
+
+	execution := &skytap.StagedExecution{
+		ActionType:        utils.String("start"),
+		CurrentStageIndex: utils.Int(1),
+		VMIDs:             []string{"2", "3"},
+	}
+	flattened := flattenStagedExecution(execution)
+	assert.Len(t, flattened, 1)
+
+	result := flattened[0].(map[string]interface{})
+	assert.Equal(t, "start", result["action_type"])
+	assert.Equal(t, 1, result["current_stage_index"])
+	assert.Equal(t, []interface{}{"2", "3"}, result["vm_ids"])
+	assert.NotContains(t, result, "current_stage_finished_at")
+}
+
+func TestFlattenEnvironmentAttributes(t *testing.T) {
+	var environment skytap.Environment
+	err := json.Unmarshal(readTestFile(t, "environment_response.json"), &environment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "1", "name": "database"},
+		map[string]interface{}{"id": "2", "name": "app"},
+	}, flattenEnvironmentVMs(environment.VMs))
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "10", "name": "Default Network", "subnet": "10.0.0.0/24"},
+	}, flattenEnvironmentNetworks(environment.Networks))
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"id":           "alert-1",
+			"display_type": "environment_alert",
+			"dismissable":  false,
+			"message":      "The environment is scheduled for maintenance",
+		},
+	}, flattenAlerts(environment.Alerts))
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"x86": 4, "power": 0},
+	}, flattenSVMsByArchitecture(environment.SVMsByArchitecture))
+	assert.Empty(t, flattenSVMsByArchitecture(nil))
+}
+
+func TestFlattenProjectIDs(t *testing.T) {
+	projects := []skytap.Project{{ID: utils.Int(1)}, {ID: utils.Int(2)}}
+	assert.Equal(t, []interface{}{1, 2}, flattenProjectIDs(projects))
+}
+
 func readTestFile(t *testing.T, name string) []byte {
 	path := filepath.Join("testdata", name) // relative path
 	bytes, err := ioutil.ReadFile(path)
@@ -119,3 +227,54 @@ func readTestFile(t *testing.T, name string) []byte {
 	}
 	return bytes
 }
+
+func TestParseEnvironmentChildID(t *testing.T) {
+	environmentID, id, err := parseEnvironmentChildID("123/456")
+	assert.NoError(t, err)
+	assert.Equal(t, "123", environmentID)
+	assert.Equal(t, "456", id)
+
+	for _, v := range []string{"", "123", "123/", "/456", "123/456/789"} {
+		_, _, err = parseEnvironmentChildID(v)
+		assert.Error(t, err, fmt.Sprintf("expecting an error for ID: %s", v))
+	}
+}
+
+func TestParseImportID(t *testing.T) {
+	parts, err := parseImportID("123/456/789", "environment_id", "vm_id", "id")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"123", "456", "789"}, parts)
+
+	for _, v := range []string{"", "123/456", "123//789", "123/456/789/0"} {
+		_, err = parseImportID(v, "environment_id", "vm_id", "id")
+		assert.EqualError(t, err, fmt.Sprintf("unexpected format of ID (%s), expected environment_id/vm_id/id", v))
+	}
+}
+
+func TestImportStateInterfaceChild(t *testing.T) {
+	d := resourceSkytapPublishedService().TestResourceData()
+
+	d.SetId("123/456/nic-1/789")
+	_, err := importStateInterfaceChild(context.TODO(), d, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "789", d.Id())
+	assert.Equal(t, "123", d.Get("environment_id"))
+	assert.Equal(t, "456", d.Get("vm_id"))
+	assert.Equal(t, "nic-1", d.Get("interface_id"))
+
+	d.SetId("123/456/789")
+	_, err = importStateInterfaceChild(context.TODO(), d, nil)
+	assert.Error(t, err)
+}
+
+func TestImportStateIntID(t *testing.T) {
+	d := resourceSkytapProject().TestResourceData()
+
+	d.SetId("123")
+	_, err := importStateIntID("project")(context.TODO(), d, nil)
+	assert.NoError(t, err)
+
+	d.SetId("abc")
+	_, err = importStateIntID("project")(context.TODO(), d, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"log"
+	"sync"
+)
+
+// MutexKV is a set of mutexes keyed by string, used to serialize the changes made to the same remote object
+// by different resources
+type MutexKV struct {
+	lock  sync.Mutex
+	store map[string]*sync.Mutex
+}
+
+// NewMutexKV returns an empty MutexKV
+func NewMutexKV() *MutexKV {
+	return &MutexKV{
+		store: make(map[string]*sync.Mutex),
+	}
+}
+
+// Lock locks the mutex for the given key, waiting until it is available
+func (m *MutexKV) Lock(key string) {
+	log.Printf("[DEBUG] locking %q", key)
+	m.get(key).Lock()
+	log.Printf("[DEBUG] locked %q", key)
+}
+
+// Unlock unlocks the mutex for the given key
+func (m *MutexKV) Unlock(key string) {
+	log.Printf("[DEBUG] unlocking %q", key)
+	m.get(key).Unlock()
+	log.Printf("[DEBUG] unlocked %q", key)
+}
+
+func (m *MutexKV) get(key string) *sync.Mutex {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	mutex, ok := m.store[key]
+	if !ok {
+		mutex = &sync.Mutex{}
+		m.store[key] = mutex
+	}
+	return mutex
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutexKV(t *testing.T) {
+	m := NewMutexKV()
+
+	m.Lock("a")
+	// a different key is not blocked
+	m.Lock("b")
+	m.Unlock("b")
+
+	unlocked := make(chan struct{})
+	go func() {
+		m.Lock("a")
+		close(unlocked)
+		m.Unlock("a")
+	}()
+
+	select {
+	case <-unlocked:
+		t.Fatal("the second lock of the same key should wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.Unlock("a")
+	select {
+	case <-unlocked:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the second lock of the same key was not acquired after unlocking")
+	}
+}
@@ -27,6 +27,11 @@ func VMRunstate(runstate skytap.VMRunstate) *skytap.VMRunstate {
 	return &runstate
 }
 
+// EnvironmentRunstate returns a pointer to an EnvironmentRunstate literal
+func EnvironmentRunstate(runstate skytap.EnvironmentRunstate) *skytap.EnvironmentRunstate {
+	return &runstate
+}
+
 // NICType returns a pointer to a NICType literal
 func NICType(nicType skytap.NICType) *skytap.NICType {
 	return &nicType
@@ -5,7 +5,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/skytap/skytap-sdk-go/skytap"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // FIXME: update validators to schema.SchemaValidateDiagFunc when the validation helper package supports it better
@@ -24,6 +26,31 @@ func validateNICType() schema.SchemaValidateFunc {
 	}, false)
 }
 
+func validateNetworkType() schema.SchemaValidateFunc {
+	return validation.StringInSlice([]string{
+		string(skytap.NetworkTypeAutomatic),
+		string(skytap.NetworkTypeManual),
+	}, false)
+}
+
+func validateEnvironmentRunstate() schema.SchemaValidateFunc {
+	return validation.StringInSlice([]string{
+		string(skytap.EnvironmentRunstateRunning),
+		string(skytap.EnvironmentRunstateSuspended),
+		string(skytap.EnvironmentRunstateStopped),
+		string(skytap.EnvironmentRunstateHalted),
+	}, false)
+}
+
+func validateVMRunstate() schema.SchemaValidateFunc {
+	return validation.StringInSlice([]string{
+		string(skytap.VMRunstateRunning),
+		string(skytap.VMRunstateStopped),
+		string(skytap.VMRunstateSuspended),
+		string(skytap.VMRunstateHalted),
+	}, false)
+}
+
 func validateRoleType() schema.SchemaValidateFunc {
 	return validation.StringInSlice([]string{
 		string(skytap.ProjectRoleViewer),
@@ -60,3 +87,28 @@ func validateNoStartWith(subString string) schema.SchemaValidateFunc {
 		return
 	}
 }
+
+// rtcStartTimeFormat is the format of the time the VM clock is set to when the VM starts
+const rtcStartTimeFormat = "2006/01/02 15:04:05"
+
+func validateRTCStartTime() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (s []string, es []error) {
+		v, ok := i.(string)
+		if !ok {
+			es = append(es, fmt.Errorf("expected type of %s to be string", k))
+			return
+		}
+		if _, err := time.Parse(rtcStartTimeFormat, v); err != nil {
+			es = append(es, fmt.Errorf("property value %s is not a time in the format YYYY/MM/DD HH:MM:SS", v))
+		}
+		return
+	}
+}
+
+func validateHostname() schema.SchemaValidateFunc {
+	return validation.All(
+		validation.StringLenBetween(1, 32),
+		validation.StringMatch(regexp.MustCompile(`^(?:[a-z0-9][a-z0-9-]*)?[a-z0-9]$`), "Valid characters are lowercase letters, numbers, and hyphens. Cannot begin or end with hyphens"),
+		validation.StringNotInSlice([]string{"gw"}, true),
+	)
+}
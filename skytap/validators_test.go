@@ -2,6 +2,7 @@ package skytap
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -29,6 +30,100 @@ func TestValidateNICType(t *testing.T) {
 	}
 }
 
+func TestValidateNetworkType(t *testing.T) {
+	x := []StringValidationTestCase{
+		// No errors
+		{TestName: "automatic", Value: string(skytap.NetworkTypeAutomatic)},
+		{TestName: "manual", Value: string(skytap.NetworkTypeManual)},
+
+		// With errors
+		{TestName: "invalid", Value: "bridged", ExpectError: true},
+		{TestName: "upper case", Value: "MANUAL", ExpectError: true},
+	}
+
+	es := testStringValidationCases(x, validateNetworkType())
+	if len(es) > 0 {
+		t.Errorf("Failed to validate network types: %v", es)
+	}
+}
+
+func TestValidateEnvironmentRunstate(t *testing.T) {
+	x := []StringValidationTestCase{
+		// No errors
+		{TestName: "running", Value: string(skytap.EnvironmentRunstateRunning)},
+		{TestName: "suspended", Value: string(skytap.EnvironmentRunstateSuspended)},
+		{TestName: "stopped", Value: string(skytap.EnvironmentRunstateStopped)},
+		{TestName: "halted", Value: string(skytap.EnvironmentRunstateHalted)},
+
+		// With errors
+		{TestName: "empty", Value: "", ExpectError: true},
+		{TestName: "busy", Value: string(skytap.EnvironmentRunstateBusy), ExpectError: true},
+	}
+
+	es := testStringValidationCases(x, validateEnvironmentRunstate())
+	if len(es) > 0 {
+		t.Errorf("Failed to validate environment runstates: %v", es)
+	}
+}
+
+func TestValidateVMRunstate(t *testing.T) {
+	x := []StringValidationTestCase{
+		// No errors
+		{TestName: "running", Value: string(skytap.VMRunstateRunning)},
+		{TestName: "stopped", Value: string(skytap.VMRunstateStopped)},
+		{TestName: "suspended", Value: string(skytap.VMRunstateSuspended)},
+		{TestName: "halted", Value: string(skytap.VMRunstateHalted)},
+
+		// With errors
+		{TestName: "empty", Value: "", ExpectError: true},
+		{TestName: "busy", Value: string(skytap.VMRunstateBusy), ExpectError: true},
+		{TestName: "reset", Value: string(skytap.VMRunstateReset), ExpectError: true},
+	}
+
+	es := testStringValidationCases(x, validateVMRunstate())
+	if len(es) > 0 {
+		t.Errorf("Failed to validate VM runstates: %v", es)
+	}
+}
+
+func TestValidateRTCStartTime(t *testing.T) {
+	x := []StringValidationTestCase{
+		// No errors
+		{TestName: "valid", Value: "2019/06/19 15:40:42"},
+
+		// With errors
+		{TestName: "empty", Value: "", ExpectError: true},
+		{TestName: "date only", Value: "2019/06/19", ExpectError: true},
+		{TestName: "iso", Value: "2019-06-19T15:40:42Z", ExpectError: true},
+	}
+
+	es := testStringValidationCases(x, validateRTCStartTime())
+	if len(es) > 0 {
+		t.Errorf("Failed to validate RTC start times: %v", es)
+	}
+}
+
+func TestValidateHostname(t *testing.T) {
+	x := []StringValidationTestCase{
+		// No errors
+		{TestName: "single", Value: "a"},
+		{TestName: "hyphen", Value: "my-host1"},
+
+		// With errors
+		{TestName: "empty", Value: "", ExpectError: true},
+		{TestName: "uppercase", Value: "MyHost", ExpectError: true},
+		{TestName: "leading hyphen", Value: "-host", ExpectError: true},
+		{TestName: "trailing hyphen", Value: "host-", ExpectError: true},
+		{TestName: "too long", Value: strings.Repeat("a", 33), ExpectError: true},
+		{TestName: "gateway", Value: "gw", ExpectError: true},
+	}
+
+	es := testStringValidationCases(x, validateHostname())
+	if len(es) > 0 {
+		t.Errorf("Failed to validate hostnames: %v", es)
+	}
+}
+
 func TestValidateRoleType(t *testing.T) {
 	x := []StringValidationTestCase{
 		// No errors